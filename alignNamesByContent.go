@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Function to hash every regular file directly inside a folder, returning hash -> sorted names
+func hashFolder(folderPath string) (map[string][]string, map[string]string, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var paths []string
+	for _, file := range files {
+		if file.Mode().IsRegular() {
+			paths = append(paths, filepath.Join(folderPath, file.Name()))
+		}
+	}
+
+	hashes, err := hashFiles(paths, hashConcurrency(Options{}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byHash := make(map[string][]string)
+	for path, hash := range hashes {
+		byHash[hash] = append(byHash[hash], filepath.Base(path))
+	}
+	for _, names := range byHash {
+		sort.Strings(names)
+	}
+
+	return byHash, hashes, nil
+}
+
+// Function to rename files in badDir to the name of the file with identical
+// content in refDir. Files without a match or with several candidate names
+// are skipped, as are files already carrying the matching name, and existing
+// targets are never overwritten.
+func alignNamesByContent(badDir string, refDir string) ([]RenameResult, error) {
+	return alignNamesByContentWithOptions(badDir, refDir, Options{})
+}
+
+// Function to align names by content, recording renames in opts.Manifest
+func alignNamesByContentWithOptions(badDir string, refDir string, opts Options) ([]RenameResult, error) {
+
+	refByHash, _, err := hashFolder(refDir)
+	if err != nil {
+		return nil, err
+	}
+
+	_, badHashes, err := hashFolder(badDir)
+	if err != nil {
+		return nil, err
+	}
+
+	badPaths := make([]string, 0, len(badHashes))
+	for path := range badHashes {
+		badPaths = append(badPaths, path)
+	}
+	sort.Strings(badPaths)
+
+	results := []RenameResult{}
+	for _, path := range badPaths {
+
+		result := RenameResult{OldPath: path}
+		names := refByHash[badHashes[path]]
+
+		switch {
+		case len(names) == 0:
+			result.Status = statusSkipped
+			result.Reason = "no file with the same content in " + refDir
+		case len(names) > 1:
+			result.Status = statusSkipped
+			result.Reason = "content matches several files in " + refDir + ": " + strings.Join(names, ", ")
+		}
+		if result.Status != "" {
+			results = append(results, result)
+			continue
+		}
+
+		result.NewPath = filepath.Join(badDir, names[0])
+		if result.NewPath == path {
+			result.Status = statusSkipped
+			result.Reason = "already has the matching name"
+		} else if _, err := os.Lstat(result.NewPath); err == nil {
+			result.Status = statusFailed
+			result.Reason = "target already exists"
+		} else if err := os.Rename(path, result.NewPath); err != nil {
+			result.Status = statusFailed
+			result.Reason = err.Error()
+		} else {
+			result.Status = statusRenamed
+		}
+		results = append(results, result)
+	}
+
+	opts.Manifest.recordResults(operationRename, results)
+
+	return results, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAlignNamesByContent(t *testing.T) {
+
+	refDir, badDir := t.TempDir(), t.TempDir()
+	writeTestFiles(t, refDir, map[string]string{
+		"holiday.jpg": "beach",
+		"report.pdf":  "numbers",
+		"same.txt":    "unchanged",
+		"twin_a.bin":  "twin",
+		"twin_b.bin":  "twin",
+		"taken.txt":   "taken content",
+	})
+	writeTestFiles(t, badDir, map[string]string{
+		"IMG_0001.jpg": "beach",
+		"scan 3.pdf":   "numbers",
+		"same.txt":     "unchanged",
+		"copy.bin":     "twin",
+		"orphan.dat":   "nothing like it",
+		"other.txt":    "taken content",
+		"taken.txt":    "something else",
+	})
+
+	results, err := alignNamesByContent(badDir, refDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct{ status, newName string }{
+		"IMG_0001.jpg": {statusRenamed, "holiday.jpg"},
+		"scan 3.pdf":   {statusRenamed, "report.pdf"},
+		"same.txt":     {statusSkipped, "same.txt"},
+		"copy.bin":     {statusSkipped, ""},
+		"orphan.dat":   {statusSkipped, ""},
+		"other.txt":    {statusFailed, "taken.txt"},
+		"taken.txt":    {statusSkipped, ""},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v, want one per file in %s", results, badDir)
+	}
+	for _, result := range results {
+		w := want[filepath.Base(result.OldPath)]
+		newName := ""
+		if result.NewPath != "" {
+			newName = filepath.Base(result.NewPath)
+		}
+		if result.Status != w.status || newName != w.newName {
+			t.Errorf("%s: %s -> %q (%s), want %s -> %q", filepath.Base(result.OldPath), result.Status, newName, result.Reason, w.status, w.newName)
+		}
+	}
+
+	got := listTestFiles(t, badDir)
+	wantFiles := []string{"copy.bin", "holiday.jpg", "orphan.dat", "other.txt", "report.pdf", "same.txt", "taken.txt"}
+	if !equalStrings(got, wantFiles) {
+		t.Errorf("files = %v, want %v", got, wantFiles)
+	}
+	if got := readTestFile(t, badDir, "taken.txt"); got != "something else" {
+		t.Errorf("existing target was overwritten with %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Name of the folder old files are swept into
+const archiveFolderName = "archive"
+
+// archiveNow is swapped out to pin the clock the age threshold is measured against
+var archiveNow = time.Now
+
+// Function to move files older than a threshold into an archive folder
+func archiveOldFiles(folderPath string, olderThan time.Duration) ([]RenameResult, error) {
+	return archiveOldFilesWithOptions(folderPath, olderThan, Options{})
+}
+
+// Function to move old files into folderPath/archive, optionally recursively or
+// as a dry run. Only files strictly older than the threshold move; a file
+// exactly olderThan old stays.
+func archiveOldFilesWithOptions(folderPath string, olderThan time.Duration, opts Options) ([]RenameResult, error) {
+
+	archiveDir := filepath.Join(folderPath, archiveFolderName)
+	cutoff := archiveNow().Add(-olderThan)
+
+	var old []string
+	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path == archiveDir || (path != folderPath && !opts.Recursive) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Mode().IsRegular() && info.ModTime().Before(cutoff) {
+			old = append(old, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[string]bool)
+	results := []RenameResult{}
+	for _, path := range old {
+
+		if opts.DryRun {
+			dst := uniquePathAvoiding(filepath.Join(archiveDir, filepath.Base(path)), claimed)
+			claimed[dst] = true
+			results = append(results, RenameResult{OldPath: path, NewPath: dst, Status: statusPlanned})
+			continue
+		}
+
+		result := RenameResult{OldPath: path, NewPath: filepath.Join(archiveDir, filepath.Base(path))}
+		if dst, err := moveFile(path, archiveDir); err != nil {
+			result.Status = statusFailed
+			result.Reason = err.Error()
+		} else {
+			result.NewPath = dst
+			result.Status = statusRenamed
+		}
+		opts.Manifest.record(operationMove, result.OldPath, result.NewPath, result.Status)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
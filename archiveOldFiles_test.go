@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Function to pin the archive clock for one test
+func pinArchiveNow(t *testing.T, now time.Time) {
+	t.Helper()
+
+	archiveNow = func() time.Time { return now }
+	t.Cleanup(func() { archiveNow = time.Now })
+}
+
+func TestArchiveOldFilesThreshold(t *testing.T) {
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	pinArchiveNow(t, now)
+	threshold := 30 * 24 * time.Hour
+	cutoff := now.Add(-threshold)
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"ancient.txt": "", "just_over.txt": "", "exactly.txt": "", "just_under.txt": "", "sub/old.txt": ""})
+	backdate(t, dir, "ancient.txt", cutoff.Add(-365*24*time.Hour))
+	backdate(t, dir, "just_over.txt", cutoff.Add(-time.Second))
+	backdate(t, dir, "exactly.txt", cutoff)
+	backdate(t, dir, "just_under.txt", cutoff.Add(time.Second))
+	backdate(t, dir, "sub/old.txt", cutoff.Add(-time.Hour))
+
+	results, err := archiveOldFiles(dir, threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countStatus(results, statusRenamed) != 2 {
+		t.Errorf("results = %+v, want 2 archived", results)
+	}
+
+	want := []string{"archive/ancient.txt", "archive/just_over.txt", "exactly.txt", "just_under.txt", "sub/old.txt"}
+	if got := listTestFiles(t, dir); !equalStrings(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+
+	// The archive folder itself is never swept again
+	again, err := archiveOldFiles(dir, threshold)
+	if err != nil || len(again) != 0 {
+		t.Errorf("second run = %+v, %v", again, err)
+	}
+}
+
+func TestArchiveOldFilesRecursiveDryRun(t *testing.T) {
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	pinArchiveNow(t, now)
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a/log.txt": "", "b/log.txt": "", "new.txt": ""})
+	backdate(t, dir, "a/log.txt", now.Add(-48*time.Hour))
+	backdate(t, dir, "b/log.txt", now.Add(-48*time.Hour))
+
+	results, err := archiveOldFilesWithOptions(dir, 24*time.Hour, Options{Recursive: true, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 || countStatus(results, statusPlanned) != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+	if filepath.Base(results[0].NewPath) == filepath.Base(results[1].NewPath) {
+		t.Errorf("dry run planned both files onto %s", results[0].NewPath)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a/log.txt", "b/log.txt", "new.txt"}) {
+		t.Errorf("dry run changed files: %v", got)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Function to tell which archive format a file name implies, or "" if none
+func archiveKind(name string) string {
+
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+
+	return ""
+}
+
+// Function to list the entry names of a .zip, .tar, .tar.gz or .tgz file without extracting it
+func listArchiveContents(path string) ([]string, error) {
+
+	switch archiveKind(path) {
+	case "zip":
+		reader, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		names := make([]string, 0, len(reader.File))
+		for _, file := range reader.File {
+			names = append(names, file.Name)
+		}
+		return names, nil
+
+	case "tar", "tar.gz":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		var r io.Reader = file
+		if archiveKind(path) == "tar.gz" {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				return nil, err
+			}
+			defer gz.Close()
+			r = gz
+		}
+		return listTarEntries(r)
+	}
+
+	return nil, fmt.Errorf("%s is not a supported archive", path)
+}
+
+// Function to read the entry names of a tar stream
+func listTarEntries(r io.Reader) ([]string, error) {
+
+	names := []string{}
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, header.Name)
+	}
+}
+
+// Function to list the contents of every archive directly inside a folder, keyed by archive name
+func listArchivesInFolder(folderPath string) (map[string][]string, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string][]string)
+	for _, file := range files {
+
+		if archiveKind(file.Name()) == "" {
+			continue
+		}
+
+		names, err := listArchiveContents(filepath.Join(folderPath, file.Name()))
+		if err != nil {
+			return contents, fmt.Errorf("%s: %v", file.Name(), err)
+		}
+		contents[file.Name()] = names
+	}
+
+	return contents, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var archiveEntries = []string{"readme.txt", "docs/", "docs/guide.md"}
+
+// Function to write a zip holding archiveEntries
+func writeTestZip(t *testing.T, path string) {
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	w := zip.NewWriter(file)
+	for _, name := range archiveEntries {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(entry, "content of "+name)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Function to write a tar holding archiveEntries, gzipped when compress is set
+func writeTestTar(t *testing.T, path string, compress bool) {
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var out io.Writer = file
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(file)
+		out = gz
+	}
+
+	w := tar.NewWriter(out)
+	for _, name := range archiveEntries {
+		content := "content of " + name
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if name[len(name)-1] == '/' {
+			header.Typeflag, header.Size, content = tar.TypeDir, 0, ""
+		}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, content)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestListArchiveContents(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestZip(t, filepath.Join(dir, "bundle.zip"))
+	writeTestTar(t, filepath.Join(dir, "bundle.tar"), false)
+	writeTestTar(t, filepath.Join(dir, "bundle.tar.gz"), true)
+	writeTestTar(t, filepath.Join(dir, "bundle.TGZ"), true)
+	writeTestFiles(t, dir, map[string]string{"notes.txt": "not an archive"})
+
+	contents, err := listArchivesInFolder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(contents) != 4 {
+		t.Errorf("listed %d archives, want 4: %v", len(contents), contents)
+	}
+	for name, entries := range contents {
+		if !reflect.DeepEqual(entries, archiveEntries) {
+			t.Errorf("%s entries = %v, want %v", name, entries, archiveEntries)
+		}
+	}
+
+	// Nothing is extracted
+	if got := listTestFiles(t, dir); len(got) != 5 {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestListArchiveContentsErrors(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"broken.zip": "not a zip", "broken.tar.gz": "not gzip", "notes.txt": ""})
+
+	for _, name := range []string{"broken.zip", "broken.tar.gz", "notes.txt"} {
+		if _, err := listArchiveContents(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s was listed", name)
+		}
+	}
+}
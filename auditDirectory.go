@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Warning categories reported by auditDirectory
+const (
+	warningSymlinkLoop  = "symlink-loop"
+	warningUnreadable   = "unreadable"
+	warningControlChars = "control-characters"
+	warningSpecialFile  = "special-file"
+)
+
+// Warning is one potential problem found by a pre-flight scan
+type Warning struct {
+	Category string `json:"category"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// Function to tell whether a name contains control characters
+func hasControlChars(name string) bool {
+
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Function to tell whether a symlink loops back on itself or an ancestor
+func isSymlinkLoop(path string) (bool, string) {
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if errors.Is(err, syscall.ELOOP) || strings.Contains(err.Error(), "too many links") {
+			return true, "symlink resolves in a loop"
+		}
+		return false, ""
+	}
+
+	info, err := os.Stat(target)
+	if err != nil || !info.IsDir() {
+		return false, ""
+	}
+
+	// A link to one of its own parent folders makes following walks recurse forever
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return false, ""
+	}
+	if rel, err := filepath.Rel(target, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return true, "symlink points to its own ancestor " + target
+	}
+
+	return false, ""
+}
+
+// Function to scan a tree read-only for things that commonly break destructive operations
+func auditDirectory(root string) ([]Warning, error) {
+
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+
+	warnings := []Warning{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsPermission(err) {
+				warnings = append(warnings, Warning{warningUnreadable, path, err.Error()})
+				return nil
+			}
+			return err
+		}
+
+		if path != root && hasControlChars(info.Name()) {
+			warnings = append(warnings, Warning{warningControlChars, path, "name contains control characters"})
+		}
+
+		mode := info.Mode()
+		switch {
+		case mode&os.ModeSymlink != 0:
+			if loop, message := isSymlinkLoop(path); loop {
+				warnings = append(warnings, Warning{warningSymlinkLoop, path, message})
+			}
+		case mode&(os.ModeDevice|os.ModeCharDevice|os.ModeNamedPipe|os.ModeSocket|os.ModeIrregular) != 0:
+			warnings = append(warnings, Warning{warningSpecialFile, path, "not a regular file: " + mode.Type().String()})
+		case mode.IsRegular():
+			file, err := os.Open(path)
+			if err != nil {
+				if os.IsPermission(err) {
+					warnings = append(warnings, Warning{warningUnreadable, path, err.Error()})
+				}
+				return nil
+			}
+			file.Close()
+		}
+
+		return nil
+	})
+
+	return warnings, err
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestHasControlChars(t *testing.T) {
+
+	for name, want := range map[string]bool{"plain.txt": false, "tab\tname": true, "bell\x07": true, "del\x7f": true, "ünïcode": false} {
+		if got := hasControlChars(name); got != want {
+			t.Errorf("hasControlChars(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestAuditDirectoryCleanTree(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "sub/b.txt": "b"})
+
+	warnings, err := auditDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("clean tree warned %v", warnings)
+	}
+}
@@ -0,0 +1,88 @@
+//go:build linux || darwin || freebsd
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// Function to audit dir and return the warnings in the given category
+func auditCategory(t *testing.T, dir string, category string) []Warning {
+	t.Helper()
+
+	warnings, err := auditDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var matched []Warning
+	for _, warning := range warnings {
+		if warning.Category == category {
+			matched = append(matched, warning)
+		}
+	}
+
+	return matched
+}
+
+func TestAuditDirectorySymlinkLoop(t *testing.T) {
+
+	dir := t.TempDir()
+	if err := os.Symlink("self", filepath.Join(dir, "self")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("..", filepath.Join(dir, "sub", "up")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := auditCategory(t, dir, warningSymlinkLoop); len(got) != 2 {
+		t.Errorf("symlink loop warnings = %v, want 2", got)
+	}
+}
+
+func TestAuditDirectoryUnreadable(t *testing.T) {
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can read everything")
+	}
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"secret.txt": "s", "locked/inner.txt": "i"})
+	os.Chmod(filepath.Join(dir, "secret.txt"), 0)
+	os.Chmod(filepath.Join(dir, "locked"), 0)
+	defer os.Chmod(filepath.Join(dir, "locked"), 0755)
+
+	if got := auditCategory(t, dir, warningUnreadable); len(got) != 2 {
+		t.Errorf("unreadable warnings = %v, want 2", got)
+	}
+}
+
+func TestAuditDirectoryControlCharacters(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"bad\x01name.txt": "", "fine.txt": ""})
+
+	got := auditCategory(t, dir, warningControlChars)
+	if len(got) != 1 || filepath.Base(got[0].Path) != "bad\x01name.txt" {
+		t.Errorf("control character warnings = %v", got)
+	}
+}
+
+func TestAuditDirectorySpecialFile(t *testing.T) {
+
+	dir := t.TempDir()
+	if err := syscall.Mkfifo(filepath.Join(dir, "pipe"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := auditCategory(t, dir, warningSpecialFile)
+	if len(got) != 1 || filepath.Base(got[0].Path) != "pipe" {
+		t.Errorf("special file warnings = %v", got)
+	}
+}
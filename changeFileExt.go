@@ -1,49 +1,170 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"strings"
 )
 
 // Function to change file extensions
 func changeFileExtensions(oldExt string, newExt string, folderPath string) string {
 
-	if !strings.Contains(oldExt, ".") {
-		oldExt = "." + oldExt
+	results, err := changeFileExtensionsWithOptions(oldExt, newExt, folderPath, Options{})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return err.Error()
+	}
+	for _, result := range results {
+		printResult(result)
 	}
 
-	if !strings.Contains(newExt, ".") {
-		newExt = "." + newExt
+	return "Change File Extension"
+}
+
+// Function to change file extensions with extra options, returning every result
+func changeFileExtensionsWithOptions(oldExt string, newExt string, folderPath string, opts Options) ([]RenameResult, error) {
+
+	plan, err := planExtensionChangeWithOptions(oldExt, newExt, folderPath, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	files, err := ioutil.ReadDir(folderPath)
+	if conflicts := preflightConflicts(plan); len(conflicts) > 0 {
+		return plan, &ConflictError{Conflicts: conflicts}
+	}
+
+	if err := checkExpectedCount(plan, opts.Expect); err != nil {
+		return plan, err
+	}
+
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	return applyExtensionPlan(plan, folderPath, opts)
+}
+
+// Function to plan an extension change in one folder, honouring the git,
+// confinement and target folder options, without checking for conflicts
+func planExtensionChangeWithOptions(oldExt string, newExt string, folderPath string, opts Options) ([]RenameResult, error) {
+
+	snapshot, err := scanSnapshot(folderPath, opts.Recursive)
 	if err != nil {
-		fmt.Println("Error:", err)
-		return err.Error()
+		return nil, err
+	}
+
+	if opts.GitChanged {
+		if err := filterSnapshotToGitChanged(snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := planExtensionChange(oldExt, newExt, snapshot)
+	if opts.ConfineToRoot {
+		if err := confinePlanToRoot(plan, folderPath); err != nil {
+			return nil, err
+		}
+	}
+	if opts.TargetDir != "" {
+		retargetPlan(plan, opts.TargetDir)
 	}
-	for _, file := range files {
 
-		if strings.HasSuffix(file.Name(), oldExt) {
+	return plan, nil
+}
 
-			oldName := folderPath + "/" + file.Name()
-			newName := strings.TrimSuffix(oldName, oldExt) + newExt
+// Function to carry out a checked extension-change plan: the inode pre-flight,
+// creating TargetDir, a staged or in-place run, and the manifest record. The
+// -edit path applies its edited plan through here too.
+func applyExtensionPlan(plan []RenameResult, folderPath string, opts Options) ([]RenameResult, error) {
 
-			err := os.Rename(oldName, newName)
-			if err != nil {
-				fmt.Printf("Failed to rename %s to %s: %v\n", oldName, newName, err)
-			} else {
-				fmt.Printf("Renamed: %s -> %s\n", oldName, newName)
-			}
+	if opts.BackupDir != "" && !opts.Staged {
+		if err := checkFreeInodes(opts.BackupDir, countStatus(plan, statusPlanned)); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Staged {
+		if err := checkFreeInodes(folderPath, countStatus(plan, statusPlanned)); err != nil {
+			return nil, err
 		}
 	}
 
-	return "Change File Extension"
+	if opts.TargetDir != "" {
+		if err := os.MkdirAll(opts.TargetDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	operation := operationRename
+	if opts.TargetDir != "" {
+		operation = operationMove
+	}
+
+	if opts.Staged {
+		results, err := applyPlanStaged(plan, folderPath, opts)
+		opts.Manifest.recordResults(operation, results)
+		return results, err
+	}
+
+	results := applyPlan(plan, folderPath, opts)
+	opts.Manifest.recordResults(operation, results)
+
+	return results, nil
+}
+
+// Function to print a single result the way the CLI always has
+func printResult(result RenameResult) {
+
+	switch result.Status {
+	case statusRenamed:
+		if result.Reason != "" {
+			fmt.Printf("Renamed: %s -> %s (%s)\n", result.OldPath, result.NewPath, result.Reason)
+		} else if result.Size > 0 {
+			fmt.Printf("Renamed: %s -> %s (%d bytes)\n", result.OldPath, result.NewPath, result.Size)
+		} else {
+			fmt.Printf("Renamed: %s -> %s\n", result.OldPath, result.NewPath)
+		}
+	case statusFailed:
+		fmt.Printf("Failed to rename %s to %s: %s\n", result.OldPath, result.NewPath, result.Reason)
+	case statusSkipped:
+		fmt.Printf("Skipped: %s (%s)\n", result.OldPath, result.Reason)
+	case statusPlanned:
+		fmt.Printf("Would rename: %s -> %s\n", result.OldPath, result.NewPath)
+	}
 }
 
 func main() {
 
+	var opts Options
+	flag.BoolVar(&opts.GitChanged, "since-git", false, "only process files git reports as changed or untracked")
+	format := flag.String("format", "text", "output format: text or grouped")
+	edit := flag.Bool("edit", false, "review and edit the planned names in $EDITOR before renaming")
+	showProgress := flag.Bool("progress", false, "show progress and an ETA on stderr")
+	expect := flag.String("expect", "", "abort unless the number of matched files is N or in the range N-M")
+	manifestPath := flag.String("manifest", "", "write a JSON record of every file touched to this path")
+	flag.Parse()
+
+	if *manifestPath != "" {
+		opts.Manifest = &Manifest{}
+		defer func() {
+			if err := opts.Manifest.writeFile(*manifestPath); err != nil {
+				fmt.Println("Error writing manifest:", err)
+			}
+		}()
+	}
+
+	if *expect != "" {
+		expectRange, err := parseCountRange(*expect)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		opts.Expect = expectRange
+	}
+
+	if *showProgress {
+		opts.Progress = newProgressRenderer(os.Stderr).update
+	}
+
 	var oldExt, newExt string
 	var folderPath string
 
@@ -56,6 +177,43 @@ func main() {
 	fmt.Println("Enter new extension (ex=>jpeg)")
 	fmt.Scan(&newExt)
 
-	changeFileExtensions(oldExt, newExt, folderPath)
+	if *edit {
+		opts.DryRun = true
+	}
+
+	results, err := changeFileExtensionsWithOptions(oldExt, newExt, folderPath, opts)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if *edit {
+		plan, err := editPlanInEditor(results, folderPath, editTargetRoot(folderPath, opts))
+		if err == errEditCancelled {
+			fmt.Println("Edit cancelled, nothing renamed")
+			return
+		}
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+
+		opts.DryRun = false
+		results, err = applyExtensionPlan(plan, folderPath, opts)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
 
+	switch *format {
+	case "grouped":
+		if err := writeResultsGrouped(os.Stdout, results); err != nil {
+			fmt.Println("Error:", err)
+		}
+	default:
+		for _, result := range results {
+			printResult(result)
+		}
+	}
 }
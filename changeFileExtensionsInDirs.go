@@ -0,0 +1,93 @@
+package main
+
+import "encoding/json"
+
+// DirResult holds the outcome of running an operation in one directory
+type DirResult struct {
+	Dir     string         `json:"dir"`
+	Results []RenameResult `json:"results"`
+	Err     error          `json:"-"`
+}
+
+// Function to serialize a DirResult with its error as a string, so a saved
+// report still says why a folder failed
+func (r DirResult) MarshalJSON() ([]byte, error) {
+
+	type plain DirResult
+	out := struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain: plain(r)}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+
+	return json.Marshal(out)
+}
+
+// Function to change file extensions in several folders at once. Every folder
+// is planned and checked for conflicts first; a folder whose own plan clashes
+// fails on its own DirResult. Destinations are then checked across the folders
+// that are left, so two folders feeding one TargetDir can't overwrite each
+// other; if such a clash is found nothing is renamed. Otherwise a failure in
+// one folder is recorded on its DirResult and the remaining folders still run.
+func changeFileExtensionsInDirs(oldExt string, newExt string, folderPaths []string, opts Options) []DirResult {
+
+	dirResults := make([]DirResult, len(folderPaths))
+	var combined []RenameResult
+	for i, folderPath := range folderPaths {
+		plan, err := planExtensionChangeWithOptions(oldExt, newExt, folderPath, opts)
+		if err == nil {
+			if conflicts := preflightConflicts(plan); len(conflicts) > 0 {
+				err = &ConflictError{Conflicts: conflicts}
+			}
+		}
+		dirResults[i] = DirResult{Dir: folderPath, Results: plan, Err: err}
+		if err == nil {
+			combined = append(combined, plan...)
+		}
+	}
+
+	// Each folder is clean on its own, so whatever clashes here crosses folders
+	if conflicts := findDestinationConflicts(combined); len(conflicts) > 0 {
+		conflictErr := &ConflictError{Conflicts: conflicts}
+		for i := range dirResults {
+			if dirResults[i].Err == nil {
+				dirResults[i].Err = conflictErr
+			}
+		}
+		return dirResults
+	}
+
+	for i := range dirResults {
+
+		dirResult := &dirResults[i]
+		if dirResult.Err != nil {
+			continue
+		}
+		if dirResult.Err = checkExpectedCount(dirResult.Results, opts.Expect); dirResult.Err != nil || opts.DryRun {
+			continue
+		}
+
+		results, err := applyExtensionPlan(dirResult.Results, dirResult.Dir, opts)
+		if results != nil {
+			dirResult.Results = results
+		}
+		dirResult.Err = err
+	}
+
+	return dirResults
+}
+
+// Function to flatten per-folder results into one list, keeping only folders that succeeded
+func combineDirResults(dirResults []DirResult) []RenameResult {
+
+	var combined []RenameResult
+	for _, dirResult := range dirResults {
+		if dirResult.Err == nil {
+			combined = append(combined, dirResult.Results...)
+		}
+	}
+
+	return combined
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChangeFileExtensionsInThreeDirs(t *testing.T) {
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"one/a.md":   "a",
+		"one/b.txt":  "b",
+		"two/c.md":   "c",
+		"two/d.md":   "d",
+		"three/e.md": "e",
+	})
+	dirs := []string{filepath.Join(root, "one"), filepath.Join(root, "missing"), filepath.Join(root, "two"), filepath.Join(root, "three")}
+
+	dirResults := changeFileExtensionsInDirs("md", "txt", dirs, Options{})
+	if len(dirResults) != 4 {
+		t.Fatalf("got %d dir results", len(dirResults))
+	}
+
+	// The missing folder fails on its own; the other three still run
+	renamed := map[string]int{}
+	for _, dirResult := range dirResults {
+		if filepath.Base(dirResult.Dir) == "missing" {
+			if dirResult.Err == nil {
+				t.Error("missing folder reported no error")
+			}
+			continue
+		}
+		if dirResult.Err != nil {
+			t.Errorf("%s: %v", dirResult.Dir, dirResult.Err)
+		}
+		renamed[filepath.Base(dirResult.Dir)] = countStatus(dirResult.Results, statusRenamed)
+	}
+	if renamed["one"] != 1 || renamed["two"] != 2 || renamed["three"] != 1 {
+		t.Errorf("renamed per folder = %v", renamed)
+	}
+	if got := len(combineDirResults(dirResults)); got != 4 {
+		t.Errorf("combined %d results, want 4", got)
+	}
+
+	want := []string{"one/a.txt", "one/b.txt", "three/e.txt", "two/c.txt", "two/d.txt"}
+	if got := listTestFiles(t, root); !equalStrings(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+
+	// The report keeps each folder's error as text
+	data, err := json.Marshal(dirResults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report []struct {
+		Dir   string `json:"dir"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report[1].Error == "" || report[0].Error != "" || strings.Contains(string(data), `"Err"`) {
+		t.Errorf("report = %s", data)
+	}
+}
+
+func TestChangeFileExtensionsInDirsChecksConflictsAcrossFolders(t *testing.T) {
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"a/x.jpeg":  "from a",
+		"b/x.jpeg":  "from b",
+		"c/y.jpeg":  "from c",
+		"out/z.jpg": "already there",
+		"d/z.jpeg":  "from d",
+	})
+	dirs := []string{filepath.Join(root, "a"), filepath.Join(root, "b"), filepath.Join(root, "c"), filepath.Join(root, "d")}
+
+	dirResults := changeFileExtensionsInDirs("jpeg", "jpg", dirs, Options{TargetDir: filepath.Join(root, "out")})
+
+	// a, b and c are held back by the clash between a and b; d by its own existing z.jpg
+	for _, dirResult := range dirResults {
+		var conflictErr *ConflictError
+		if !errors.As(dirResult.Err, &conflictErr) {
+			t.Fatalf("%s: err = %v, want a ConflictError", dirResult.Dir, dirResult.Err)
+		}
+		want := filepath.Join(root, "out", "x.jpg")
+		if filepath.Base(dirResult.Dir) == "d" {
+			want = filepath.Join(root, "out", "z.jpg")
+		}
+		if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].Destination != want {
+			t.Errorf("%s: conflicts = %+v, want only %s", dirResult.Dir, conflictErr.Conflicts, want)
+		}
+	}
+
+	// Nothing moved, including the folder that had no conflict
+	want := []string{"a/x.jpeg", "b/x.jpeg", "c/y.jpeg", "d/z.jpeg", "out/z.jpg"}
+	if got := listTestFiles(t, root); !equalStrings(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestChangeFileExtensionsInDirsKeepsFolderConflictsLocal(t *testing.T) {
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"a/x.md":  "new",
+		"a/x.txt": "precious",
+		"b/y.md":  "b",
+		"c/z.md":  "c",
+	})
+	dirs := []string{filepath.Join(root, "a"), filepath.Join(root, "b"), filepath.Join(root, "c")}
+
+	dirResults := changeFileExtensionsInDirs("md", "txt", dirs, Options{})
+
+	// Only a fails; b and c don't carry a's conflict and still run
+	var conflictErr *ConflictError
+	if !errors.As(dirResults[0].Err, &conflictErr) || len(conflictErr.Conflicts) != 1 || !conflictErr.Conflicts[0].Exists {
+		t.Errorf("a: err = %v, want its existing x.txt", dirResults[0].Err)
+	}
+	for _, dirResult := range dirResults[1:] {
+		if dirResult.Err != nil {
+			t.Errorf("%s: %v", dirResult.Dir, dirResult.Err)
+		}
+		if got := countStatus(dirResult.Results, statusRenamed); got != 1 {
+			t.Errorf("%s: renamed %d, want 1", dirResult.Dir, got)
+		}
+	}
+
+	want := []string{"a/x.md", "a/x.txt", "b/y.txt", "c/z.txt"}
+	if got := listTestFiles(t, root); !equalStrings(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if got := readTestFile(t, root, "a/x.txt"); got != "precious" {
+		t.Errorf("a/x.txt = %q", got)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"path"
+	"regexp"
+)
+
+// Function to list files whose names don't match a required pattern
+func checkNamePolicy(folderPath string, pattern *regexp.Regexp) ([]string, error) {
+	return checkNamePolicyWithOptions(folderPath, pattern, Options{})
+}
+
+// Function to list files whose names don't match a pattern, optionally
+// recursively (paths relative to folderPath) and ignoring case
+func checkNamePolicyWithOptions(folderPath string, pattern *regexp.Regexp, opts Options) ([]string, error) {
+
+	if opts.IgnoreCase {
+		var err error
+		if pattern, err = regexp.Compile("(?i)" + pattern.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot, err := scanSnapshot(folderPath, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []string{}
+	for _, entry := range snapshot.Files {
+		if !pattern.MatchString(path.Base(entry.Path)) {
+			violations = append(violations, entry.Path)
+		}
+	}
+
+	return violations, nil
+}
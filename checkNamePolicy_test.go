@@ -0,0 +1,36 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestCheckNamePolicy(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"2024-01-05_report.pdf": "",
+		"2023-12-31_notes.txt":  "",
+		"IMG_1234.JPG":          "",
+		"2024-02-01_Scan.PDF":   "",
+		"sub/2024-03-03_a.txt":  "",
+		"sub/random.txt":        "",
+	})
+	pattern := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}_[a-z]+\.[a-z]+$`)
+
+	violations, err := checkNamePolicy(dir, pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2024-02-01_Scan.PDF", "IMG_1234.JPG"}; !equalStrings(violations, want) {
+		t.Errorf("violations = %v, want %v", violations, want)
+	}
+
+	violations, err = checkNamePolicyWithOptions(dir, pattern, Options{Recursive: true, IgnoreCase: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"IMG_1234.JPG", "sub/random.txt"}; !equalStrings(violations, want) {
+		t.Errorf("recursive, ignoring case: violations = %v, want %v", violations, want)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"text/template"
+	"time"
+)
+
+// Function to number files in capture order using EXIF DateTimeOriginal,
+// falling back to the modification time. Returns the mapping and the files
+// that had to fall back to their modification time.
+func chronoRenumber(folderPath string, tmpl string) (map[string]string, []string, error) {
+	return chronoRenumberWithOptions(folderPath, tmpl, Options{})
+}
+
+// Function to renumber in capture order, recording renames in opts.Manifest
+func chronoRenumberWithOptions(folderPath string, tmpl string, opts Options) (map[string]string, []string, error) {
+
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid template: %v", err)
+	}
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fellBack := []string{}
+	taken := make(map[string]time.Time)
+	for _, file := range files {
+		date, err := readEXIFDateTime(filepath.Join(folderPath, file.Name()))
+		if err != nil {
+			date = file.ModTime()
+			fellBack = append(fellBack, file.Name())
+		}
+		taken[file.Name()] = date
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := taken[files[i].Name()], taken[files[j].Name()]
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return files[i].Name() < files[j].Name()
+	})
+
+	paths, mapping, err := planTemplateRenames(folderPath, t, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkTargets(paths); err != nil {
+		return nil, nil, err
+	}
+	if err := renameInTwoPhases(paths); err != nil {
+		return nil, nil, err
+	}
+	opts.Manifest.recordPaths(operationRename, paths)
+
+	return mapping, fellBack, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestChronoRenumberMixedSources(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a.jpg": string(buildEXIFJPEG(binary.LittleEndian, "2021:07:04 10:30:00")),
+		"b.jpg": string(buildEXIFJPEG(binary.BigEndian, "2020:01:01 08:00:00")),
+		"c.jpg": "no exif here",
+		"d.jpg": "nor here",
+	})
+
+	// Modification times put the files without EXIF on both sides of the EXIF dates
+	backdate(t, dir, "c.jpg", time.Date(2022, 3, 1, 0, 0, 0, 0, time.Local))
+	backdate(t, dir, "d.jpg", time.Date(2019, 3, 1, 0, 0, 0, 0, time.Local))
+	// A modification time that would sort a.jpg first must not matter
+	backdate(t, dir, "a.jpg", time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local))
+
+	mapping, fellBack, err := chronoRenumber(dir, `photo_{{.Index}}{{.Ext}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"d.jpg": "photo_1.jpg", "b.jpg": "photo_2.jpg", "a.jpg": "photo_3.jpg", "c.jpg": "photo_4.jpg"}
+	for old, name := range want {
+		if mapping[old] != name {
+			t.Errorf("%s -> %s, want %s", old, mapping[old], name)
+		}
+	}
+	if !equalStrings(fellBack, []string{"c.jpg", "d.jpg"}) {
+		t.Errorf("fellBack = %v", fellBack)
+	}
+	if readTestFile(t, dir, "photo_4.jpg") != "no exif here" {
+		t.Error("contents did not follow the rename")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Runs of whitespace and underscores
+var separatorRun = regexp.MustCompile(`[\s_]+`)
+
+// Function to collapse runs of spaces and underscores in base names into one
+// separator. A lone space or underscore counts as a run too and is rewritten
+// to sep, so "a b_c" becomes "a-b-c" with sep "-". Extensions are left alone.
+func collapseSeparators(folderPath string, sep string) (map[string]string, error) {
+	return collapseSeparatorsWithOptions(folderPath, sep, Options{})
+}
+
+// Function to collapse separators, recording renames in opts.Manifest
+func collapseSeparatorsWithOptions(folderPath string, sep string, opts Options) (map[string]string, error) {
+
+	if sep == "" || strings.ContainsAny(sep, `/\`) {
+		return nil, fmt.Errorf("invalid separator %q", sep)
+	}
+
+	files, err := ioutil.ReadDir(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string)
+	for _, file := range files {
+
+		if file.IsDir() {
+			continue
+		}
+
+		ext := filepath.Ext(file.Name())
+		stem := strings.TrimSuffix(file.Name(), ext)
+		newName := separatorRun.ReplaceAllLiteralString(stem, sep) + ext
+		if newName == file.Name() {
+			continue
+		}
+
+		oldPath := filepath.Join(folderPath, file.Name())
+		newPath := uniqueRenameTarget(oldPath, filepath.Join(folderPath, newName), nil)
+		if newPath == oldPath {
+			continue
+		}
+
+		if err := os.Rename(oldPath, newPath); err != nil {
+			opts.Manifest.record(operationRename, oldPath, newPath, statusFailed)
+			fmt.Printf("Failed to rename %s to %s: %v\n", oldPath, newPath, err)
+			continue
+		}
+		opts.Manifest.record(operationRename, oldPath, newPath, statusRenamed)
+
+		mapping[file.Name()] = filepath.Base(newPath)
+	}
+
+	return mapping, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCollapseSeparators(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"my   holiday__photo.jpg": "1",
+		"a _ _b.txt":              "2",
+		"single space.md":         "3",
+		"tidy.txt":                "4",
+		"tab\t\tname.csv":         "5",
+	})
+
+	mapping, err := collapseSeparators(dir, "_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"my   holiday__photo.jpg": "my_holiday_photo.jpg",
+		"a _ _b.txt":              "a_b.txt",
+		"single space.md":         "single_space.md",
+		"tab\t\tname.csv":         "tab_name.csv",
+	}
+	if len(mapping) != len(want) {
+		t.Errorf("mapping = %v", mapping)
+	}
+	for old, name := range want {
+		if mapping[old] != name {
+			t.Errorf("%q -> %q, want %q", old, mapping[old], name)
+		}
+	}
+
+	// A second run has nothing left to collapse
+	again, err := collapseSeparators(dir, "_")
+	if err != nil || len(again) != 0 {
+		t.Errorf("second run = %v, %v", again, err)
+	}
+}
+
+func TestCollapseSeparatorsCollisionAndSeparator(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a  b.txt": "double", "a-b.txt": "taken"})
+
+	mapping, err := collapseSeparators(dir, "-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapping["a  b.txt"] != "a-b_1.txt" {
+		t.Errorf("collision went to %q", mapping["a  b.txt"])
+	}
+	if readTestFile(t, dir, "a-b.txt") != "taken" {
+		t.Error("existing file was overwritten")
+	}
+
+	for _, sep := range []string{"", "/", `\`} {
+		if _, err := collapseSeparators(dir, sep); err == nil {
+			t.Errorf("separator %q was accepted", sep)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Function to gzip a file next to itself (file -> file.gz) and remove the original
+// (to the trash with opts.UseTrash). On any failure the original is left
+// untouched and no partial .gz remains.
+func gzipFile(path string, opts Options) (string, int64, error) {
+
+	gzPath := path + ".gz"
+	if _, err := os.Lstat(gzPath); err == nil {
+		return "", 0, fmt.Errorf("%s already exists", gzPath)
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+
+	dst, err := os.OpenFile(gzPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return "", 0, err
+	}
+
+	writer := gzip.NewWriter(dst)
+	writer.Name = filepath.Base(path)
+	writer.ModTime = info.ModTime()
+
+	_, err = io.Copy(writer, src)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(gzPath)
+		return "", 0, err
+	}
+
+	gzInfo, err := os.Stat(gzPath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	src.Close()
+	if err := removeFile(path, opts); err != nil {
+		os.Remove(gzPath)
+		return "", 0, err
+	}
+
+	return gzPath, gzInfo.Size(), nil
+}
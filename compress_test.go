@@ -0,0 +1,89 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGzipFileRoundTrip(t *testing.T) {
+
+	dir := t.TempDir()
+	content := strings.Repeat("compress me ", 100)
+	writeTestFiles(t, dir, map[string]string{"log.txt": content})
+	path := filepath.Join(dir, "log.txt")
+
+	gzPath, size, err := gzipFile(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gzPath != path+".gz" || size <= 0 {
+		t.Errorf("gzipFile = %s, %d", gzPath, size)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Error("original was not removed")
+	}
+
+	file, err := os.Open(gzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content || reader.Name != "log.txt" {
+		t.Errorf("round trip gave %d bytes named %q", len(data), reader.Name)
+	}
+}
+
+func TestGzipFileKeepsOriginalOnFailure(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "a.txt.gz": "taken"})
+
+	if _, _, err := gzipFile(filepath.Join(dir, "a.txt"), Options{}); err == nil {
+		t.Fatal("gzip over an existing .gz succeeded")
+	}
+	if readTestFile(t, dir, "a.txt") != "a" || readTestFile(t, dir, "a.txt.gz") != "taken" {
+		t.Error("failed gzip changed the files")
+	}
+}
+
+func TestApplyPlanGzipFailureStillRenamed(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"ok.log": "fine", "stuck.log": "data", "stuck.txt.gz": "taken"})
+
+	results, err := changeFileExtensionsWithOptions("log", "txt", dir, Options{Compress: CompressGzip})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range results {
+		if result.Status != statusRenamed {
+			t.Errorf("%s is %s, want renamed", result.OldPath, result.Status)
+		}
+		switch filepath.Base(result.OldPath) {
+		case "ok.log":
+			if filepath.Base(result.NewPath) != "ok.txt.gz" || result.Reason != "" {
+				t.Errorf("ok.log -> %s (%s)", result.NewPath, result.Reason)
+			}
+		case "stuck.log":
+			if filepath.Base(result.NewPath) != "stuck.txt" || !strings.Contains(result.Reason, "gzip") {
+				t.Errorf("stuck.log -> %s (%s)", result.NewPath, result.Reason)
+			}
+		}
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"ok.txt.gz", "stuck.txt", "stuck.txt.gz"}) {
+		t.Errorf("files = %v", got)
+	}
+}
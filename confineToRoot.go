@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Function to tell whether path is root itself or somewhere below it
+func isWithin(root string, path string) bool {
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// Function to skip planned symlinks whose resolved target lies outside root
+func confinePlanToRoot(plan []RenameResult, root string) error {
+
+	resolvedRoot, err := filepath.Abs(root)
+	if err == nil {
+		resolvedRoot, err = filepath.EvalSymlinks(resolvedRoot)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, result := range plan {
+
+		if result.Status != statusPlanned {
+			continue
+		}
+
+		info, err := os.Lstat(result.OldPath)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+
+		target, err := filepath.EvalSymlinks(result.OldPath)
+		if err != nil {
+			plan[i].Status = statusSkipped
+			plan[i].Reason = "cannot resolve symlink: " + err.Error()
+			continue
+		}
+
+		if !isWithin(resolvedRoot, target) {
+			plan[i].Status = statusSkipped
+			plan[i].Reason = "symlink target " + target + " is outside " + root
+		}
+	}
+
+	return nil
+}
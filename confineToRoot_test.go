@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Function to create a symlink, skipping the test where symlinks aren't allowed
+func symlinkOrSkip(t *testing.T, target string, link string) {
+	t.Helper()
+
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not available: %v", err)
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+
+	root := filepath.FromSlash("/data/root")
+	cases := map[string]bool{
+		"/data/root":           true,
+		"/data/root/a/b":       true,
+		"/data/root/..hidden":  true,
+		"/data/rootless":       false,
+		"/data":                false,
+		"/data/root/../escape": false,
+	}
+	for path, want := range cases {
+		if got := isWithin(root, filepath.FromSlash(path)); got != want {
+			t.Errorf("isWithin(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestConfineToRootSkipsEscapingSymlinks(t *testing.T) {
+
+	outside := t.TempDir()
+	writeTestFiles(t, outside, map[string]string{"secret.txt": "outside"})
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"real.txt": "inside", "sub/inner.txt": "inner"})
+	symlinkOrSkip(t, filepath.Join(outside, "secret.txt"), filepath.Join(dir, "escape.txt"))
+	symlinkOrSkip(t, filepath.Join("sub", "inner.txt"), filepath.Join(dir, "inside.txt"))
+	symlinkOrSkip(t, filepath.Join(dir, "missing"), filepath.Join(dir, "dangling.txt"))
+
+	results, err := changeFileExtensionsWithOptions("txt", "md", dir, Options{ConfineToRoot: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := make(map[string]string)
+	for _, result := range results {
+		statuses[filepath.Base(result.OldPath)] = result.Status
+	}
+	want := map[string]string{"real.txt": statusRenamed, "inside.txt": statusRenamed, "escape.txt": statusSkipped, "dangling.txt": statusSkipped}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("%s is %q, want %q", name, statuses[name], status)
+		}
+	}
+	if readTestFile(t, outside, "secret.txt") != "outside" {
+		t.Error("file outside the root was touched")
+	}
+}
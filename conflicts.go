@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DestinationConflict is a destination more than one planned source resolves
+// to, or one that already exists on disk
+type DestinationConflict struct {
+	Destination string   `json:"destination"`
+	Sources     []string `json:"sources"`
+	Exists      bool     `json:"exists,omitempty"`
+}
+
+// ConflictError reports every destination conflict found before a run
+type ConflictError struct {
+	Conflicts []DestinationConflict
+}
+
+func (e *ConflictError) Error() string {
+
+	lines := []string{fmt.Sprintf("%d destination conflict(s):", len(e.Conflicts))}
+	for _, conflict := range e.Conflicts {
+		line := fmt.Sprintf("  %s <- %s", conflict.Destination, strings.Join(conflict.Sources, ", "))
+		if conflict.Exists {
+			line += " (already exists)"
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Function to find planned destinations that more than one source resolves to,
+// comparing absolute paths so different subfolders funnelling into one target are caught
+func findDestinationConflicts(plan []RenameResult) []DestinationConflict {
+
+	sources := make(map[string][]string)
+	for _, result := range plan {
+
+		if result.Status != statusPlanned {
+			continue
+		}
+
+		dst, err := filepath.Abs(result.NewPath)
+		if err != nil {
+			dst = filepath.Clean(result.NewPath)
+		}
+		sources[dst] = append(sources[dst], result.OldPath)
+	}
+
+	conflicts := []DestinationConflict{}
+	for dst, srcs := range sources {
+		if len(srcs) > 1 {
+			conflicts = append(conflicts, DestinationConflict{Destination: dst, Sources: srcs})
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Destination < conflicts[j].Destination
+	})
+
+	return conflicts
+}
+
+// Function to find planned destinations that already exist on disk and would be
+// overwritten. A destination that is the source itself, as with a case-only
+// change on a case-insensitive filesystem, doesn't count.
+func findExistingDestinations(plan []RenameResult) []DestinationConflict {
+
+	conflicts := []DestinationConflict{}
+	for _, result := range plan {
+
+		if result.Status != statusPlanned {
+			continue
+		}
+
+		dstInfo, err := os.Lstat(result.NewPath)
+		if err != nil {
+			continue
+		}
+		if srcInfo, err := os.Lstat(result.OldPath); err == nil && os.SameFile(srcInfo, dstInfo) {
+			continue
+		}
+
+		conflicts = append(conflicts, DestinationConflict{Destination: result.NewPath, Sources: []string{result.OldPath}, Exists: true})
+	}
+
+	return conflicts
+}
+
+// Function to run every pre-flight conflict check on a plan that targets the real disk
+func preflightConflicts(plan []RenameResult) []DestinationConflict {
+
+	conflicts := append(findDestinationConflicts(plan), findExistingDestinations(plan)...)
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Destination < conflicts[j].Destination
+	})
+
+	return conflicts
+}
+
+// Function to point every planned rename at a single target folder
+func retargetPlan(plan []RenameResult, targetDir string) {
+
+	for i := range plan {
+		plan[i].NewPath = filepath.Join(targetDir, filepath.Base(plan[i].NewPath))
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCrossSubfolderConflicts(t *testing.T) {
+
+	dir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "out")
+	writeTestFiles(t, dir, map[string]string{"a/x.txt": "a", "b/x.txt": "b", "c/y.txt": "c"})
+
+	results, err := changeFileExtensionsWithOptions("txt", "md", dir, Options{Recursive: true, TargetDir: target})
+
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("err = %v, want a ConflictError", err)
+	}
+	if len(conflictErr.Conflicts) != 1 {
+		t.Fatalf("conflicts = %+v, want 1", conflictErr.Conflicts)
+	}
+	conflict := conflictErr.Conflicts[0]
+	if filepath.Base(conflict.Destination) != "x.md" || len(conflict.Sources) != 2 || conflict.Exists {
+		t.Errorf("conflict = %+v", conflict)
+	}
+	if countStatus(results, statusRenamed) != 0 {
+		t.Error("files were renamed despite the conflict")
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a/x.txt", "b/x.txt", "c/y.txt"}) {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestExistingDestinationConflicts(t *testing.T) {
+
+	dir := t.TempDir()
+	target := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"y.jpeg": "new", "z.jpeg": "other"})
+	writeTestFiles(t, target, map[string]string{"y.jpg": "keep me"})
+
+	_, err := changeFileExtensionsWithOptions("jpeg", "jpg", dir, Options{TargetDir: target})
+
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) != 1 || !conflictErr.Conflicts[0].Exists {
+		t.Fatalf("err = %v, want one existing-destination conflict", err)
+	}
+	if readTestFile(t, target, "y.jpg") != "keep me" {
+		t.Error("existing destination was overwritten")
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"y.jpeg", "z.jpeg"}) {
+		t.Errorf("files = %v", got)
+	}
+
+	// The same check applies to in-place renames
+	writeTestFiles(t, dir, map[string]string{"z.jpg": "in the way"})
+	if _, err := changeFileExtensionsWithOptions("jpeg", "jpg", dir, Options{DryRun: true}); !errors.As(err, &conflictErr) {
+		t.Errorf("in-place err = %v, want a ConflictError", err)
+	}
+}
+
+func TestFindDestinationConflictsIgnoresUnplanned(t *testing.T) {
+
+	plan := []RenameResult{
+		{OldPath: "a", NewPath: "same", Status: statusPlanned},
+		{OldPath: "b", NewPath: "same", Status: statusSkipped},
+	}
+	if conflicts := findDestinationConflicts(plan); len(conflicts) != 0 {
+		t.Errorf("conflicts = %+v", conflicts)
+	}
+}
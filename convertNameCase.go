@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// CaseStyle is a naming convention for base names
+type CaseStyle int
+
+const (
+	CaseSnake  CaseStyle = iota // my_file_name
+	CaseKebab                   // my-file-name
+	CaseCamel                   // myFileName
+	CasePascal                  // MyFileName
+)
+
+// Function to split a name into words on separators and case boundaries,
+// keeping acronyms ("HTTPServer" -> HTTP, Server) and trailing digits ("file2Name" -> file2, Name) together
+func splitNameWords(name string) []string {
+
+	var words []string
+	var word []rune
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+
+		if unicode.IsSpace(r) || r == '_' || r == '-' || r == '.' {
+			flush()
+			continue
+		}
+
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+
+		word = append(word, r)
+	}
+	flush()
+
+	return words
+}
+
+// Function to capitalize the first letter of a word and lower-case the rest
+func titleWord(word string) string {
+
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+
+	return string(runes)
+}
+
+// Function to join words in a naming style
+func joinNameWords(words []string, style CaseStyle) string {
+
+	parts := make([]string, len(words))
+	for i, word := range words {
+		switch {
+		case style == CasePascal, style == CaseCamel && i > 0:
+			parts[i] = titleWord(word)
+		default:
+			parts[i] = strings.ToLower(word)
+		}
+	}
+
+	switch style {
+	case CaseSnake:
+		return strings.Join(parts, "_")
+	case CaseKebab:
+		return strings.Join(parts, "-")
+	}
+
+	return strings.Join(parts, "")
+}
+
+// caseProbe is swapped out to count how often convertNameCase probes the folder
+var caseProbe = probeCaseSensitive
+
+// Function to rename a file, going through a temporary name when only the
+// case changes on a case-insensitive filesystem so it picks up the new spelling
+func renameCaseSafe(oldPath string, newPath string, caseSensitive bool) error {
+
+	if caseSensitive || oldPath == newPath || !strings.EqualFold(oldPath, newPath) {
+		return os.Rename(oldPath, newPath)
+	}
+
+	tmp := uniquePath(oldPath + ".fmtmp")
+	if err := os.Rename(oldPath, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, newPath); err != nil {
+		os.Rename(tmp, oldPath)
+		return err
+	}
+
+	return nil
+}
+
+// Function to convert base names to snake_case, kebab-case, camelCase or PascalCase, keeping extensions
+func convertNameCase(folderPath string, style CaseStyle) (map[string]string, error) {
+	return convertNameCaseWithOptions(folderPath, style, Options{})
+}
+
+// Function to convert name case, recording renames in opts.Manifest
+func convertNameCaseWithOptions(folderPath string, style CaseStyle, opts Options) (map[string]string, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only case-only renames depend on it, so probe at most once and only when
+	// one comes up. Without a probe, assume the filesystem folds case and take the safe path.
+	probed, caseSensitive := false, false
+	sensitive := func() bool {
+		if !probed {
+			probed = true
+			caseSensitive, _ = caseProbe(folderPath)
+		}
+		return caseSensitive
+	}
+
+	claimed := make(map[string]bool)
+	mapping := make(map[string]string)
+	for _, file := range files {
+
+		ext := filepath.Ext(file.Name())
+		words := splitNameWords(strings.TrimSuffix(file.Name(), ext))
+		if len(words) == 0 {
+			continue
+		}
+
+		oldPath := filepath.Join(folderPath, file.Name())
+		newPath := filepath.Join(folderPath, joinNameWords(words, style)+ext)
+		if newPath == oldPath {
+			claimed[newPath] = true
+			continue
+		}
+
+		distinct := !strings.EqualFold(oldPath, newPath) || sensitive()
+		if distinct {
+			newPath = uniqueRenameTarget(oldPath, newPath, claimed)
+		}
+		claimed[newPath] = true
+		if newPath == oldPath {
+			continue
+		}
+
+		if err := renameCaseSafe(oldPath, newPath, distinct); err != nil {
+			opts.Manifest.record(operationRename, oldPath, newPath, statusFailed)
+			fmt.Printf("Failed to rename %s to %s: %v\n", oldPath, newPath, err)
+			continue
+		}
+		opts.Manifest.record(operationRename, oldPath, newPath, statusRenamed)
+
+		mapping[file.Name()] = filepath.Base(newPath)
+	}
+
+	return mapping, nil
+}
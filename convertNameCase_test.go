@@ -0,0 +1,116 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJoinNameWordsStyles(t *testing.T) {
+
+	cases := []struct {
+		name                        string
+		snake, kebab, camel, pascal string
+	}{
+		{"my file name", "my_file_name", "my-file-name", "myFileName", "MyFileName"},
+		{"HTTPServer_config", "http_server_config", "http-server-config", "httpServerConfig", "HttpServerConfig"},
+		{"parseJSON2Yaml", "parse_json2_yaml", "parse-json2-yaml", "parseJson2Yaml", "ParseJson2Yaml"},
+		{"report-2024-Q3", "report_2024_q3", "report-2024-q3", "report2024Q3", "Report2024Q3"},
+		{"getURLForID", "get_url_for_id", "get-url-for-id", "getUrlForId", "GetUrlForId"},
+		{"IMG_0042", "img_0042", "img-0042", "img0042", "Img0042"},
+	}
+
+	for _, c := range cases {
+		words := splitNameWords(c.name)
+		for style, want := range map[CaseStyle]string{CaseSnake: c.snake, CaseKebab: c.kebab, CaseCamel: c.camel, CasePascal: c.pascal} {
+			if got := joinNameWords(words, style); got != want {
+				t.Errorf("%q style %d = %q, want %q", c.name, style, got, want)
+			}
+		}
+	}
+}
+
+func TestConvertNameCase(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"My Report.PDF":   "a",
+		"my_report.txt":   "b",
+		"HTTPServer.go":   "c",
+		"already_ok.md":   "d",
+		"photo2Edit.jpg":  "e",
+		"photo2-edit.png": "f",
+	})
+
+	mapping, err := convertNameCase(dir, CaseSnake)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"My Report.PDF":   "my_report.PDF",
+		"HTTPServer.go":   "http_server.go",
+		"photo2Edit.jpg":  "photo2_edit.jpg",
+		"photo2-edit.png": "photo2_edit.png",
+	}
+	if !reflect.DeepEqual(mapping, want) {
+		t.Errorf("mapping = %v, want %v", mapping, want)
+	}
+
+	// Converting to camelCase from the snake names round-trips the words
+	mapping, err = convertNameCase(dir, CaseCamel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapping["http_server.go"] != "httpServer.go" || mapping["my_report.PDF"] != "myReport.PDF" {
+		t.Errorf("camel mapping = %v", mapping)
+	}
+}
+
+func TestConvertNameCaseCollision(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"File Name.txt": "a", "file-name.txt": "b", "fileName.txt": "c"})
+
+	if _, err := convertNameCase(dir, CaseSnake); err != nil {
+		t.Fatal(err)
+	}
+
+	got := listTestFiles(t, dir)
+	want := []string{"file_name.txt", "file_name_1.txt", "file_name_2.txt"}
+	if !equalStrings(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestConvertNameCaseProbesOnlyForCaseOnlyRenames(t *testing.T) {
+
+	probes := 0
+	caseProbe = func(path string) (bool, error) {
+		probes++
+		return probeCaseSensitive(path)
+	}
+	defer func() { caseProbe = probeCaseSensitive }()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"My File.txt": "a", "already_snake.txt": "b"})
+	if _, err := convertNameCase(dir, CaseSnake); err != nil {
+		t.Fatal(err)
+	}
+	if probes != 0 {
+		t.Errorf("probed %d times with no case-only rename", probes)
+	}
+
+	// Two case-only renames share one probe, which leaves nothing behind
+	writeTestFiles(t, dir, map[string]string{"Report.md": "c", "Summary.md": "d"})
+	if _, err := convertNameCase(dir, CaseSnake); err != nil {
+		t.Fatal(err)
+	}
+	if probes != 1 {
+		t.Errorf("probed %d times, want once", probes)
+	}
+
+	want := []string{"already_snake.txt", "my_file.txt", "report.md", "summary.md"}
+	if got := listTestFiles(t, dir); !equalStrings(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
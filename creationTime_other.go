@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// Function to carry over creation time after a copy; Unix birthtimes can't be
+// set reliably, so this is a no-op outside Windows
+func preserveCreationTime(src string, dst string) error {
+	return nil
+}
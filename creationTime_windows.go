@@ -0,0 +1,38 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// Function to give dst the same creation time as src, since a copy gets a fresh one
+func preserveCreationTime(src string, dst string) error {
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return nil
+	}
+
+	path, err := syscall.UTF16PtrFromString(dst)
+	if err != nil {
+		return err
+	}
+
+	handle, err := syscall.CreateFile(path, syscall.FILE_WRITE_ATTRIBUTES,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE, nil,
+		syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return err
+	}
+	defer syscall.CloseHandle(handle)
+
+	creationTime := data.CreationTime
+	return syscall.SetFileTime(handle, &creationTime, nil, nil)
+}
@@ -0,0 +1,47 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Function to read a file's creation time on Windows
+func creationTime(t *testing.T, path string) time.Time {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return time.Unix(0, info.Sys().(*syscall.Win32FileAttributeData).CreationTime.Nanoseconds())
+}
+
+func TestPreserveCreationTime(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"src.txt": "original"})
+	src := filepath.Join(dir, "src.txt")
+
+	// Make sure the copy would get a visibly newer creation time on its own
+	time.Sleep(50 * time.Millisecond)
+	dst := filepath.Join(dir, "dst.txt")
+	if err := copyFile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if creationTime(t, dst).Equal(creationTime(t, src)) {
+		t.Skip("filesystem did not give the copy a new creation time")
+	}
+
+	if err := preserveCreationTime(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := creationTime(t, dst), creationTime(t, src); !got.Equal(want) {
+		t.Errorf("creation time = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Function to group files with identical content by their sha256.
+// Only groups with more than one file are returned.
+func findDuplicates(folderPath string) (map[string][]string, error) {
+	return findDuplicatesWithOptions(folderPath, Options{})
+}
+
+// Function to group duplicate files, hashing up to opts.MaxConcurrentHashes files at once
+func findDuplicatesWithOptions(folderPath string, opts Options) (map[string][]string, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files can only match if their sizes do, so only hash those
+	bySize := make(map[int64][]string)
+	for _, file := range files {
+		if file.Mode().IsRegular() {
+			bySize[file.Size()] = append(bySize[file.Size()], filepath.Join(folderPath, file.Name()))
+		}
+	}
+
+	var candidates []string
+	for _, paths := range bySize {
+		if len(paths) > 1 {
+			candidates = append(candidates, paths...)
+		}
+	}
+
+	hashes, err := hashFiles(candidates, hashConcurrency(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	byHash := make(map[string][]string)
+	for path, hash := range hashes {
+		byHash[hash] = append(byHash[hash], path)
+	}
+
+	duplicates := make(map[string][]string)
+	for hash, paths := range byHash {
+		if len(paths) > 1 {
+			sort.Strings(paths)
+			duplicates[hash] = paths
+		}
+	}
+
+	return duplicates, nil
+}
+
+// Function to estimate the bytes a dedup would free: every copy but one per group
+func dedupSavings(folderPath string) (int64, error) {
+
+	duplicates, err := findDuplicates(folderPath)
+	if err != nil {
+		return 0, err
+	}
+
+	var savings int64
+	for _, paths := range duplicates {
+		info, err := os.Stat(paths[0])
+		if err != nil {
+			return 0, err
+		}
+		savings += info.Size() * int64(len(paths)-1)
+	}
+
+	return savings, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindDuplicatesAndSavings(t *testing.T) {
+
+	dir := t.TempDir()
+	hundred := strings.Repeat("x", 100)
+	writeTestFiles(t, dir, map[string]string{
+		"a.bin":      hundred,
+		"a copy.bin": hundred,
+		"a again":    hundred,
+		"b.bin":      strings.Repeat("y", 40),
+		"b copy.bin": strings.Repeat("y", 40),
+		// Same size as the b pair but different bytes
+		"c.bin":      strings.Repeat("z", 40),
+		"unique.bin": "only one",
+		"sub/a.bin":  hundred,
+	})
+
+	duplicates, err := findDuplicatesWithOptions(dir, Options{MaxConcurrentHashes: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(duplicates) != 2 {
+		t.Fatalf("duplicates = %v, want 2 groups", duplicates)
+	}
+	for _, paths := range duplicates {
+		if len(paths) != 3 && len(paths) != 2 {
+			t.Errorf("group %v has the wrong size", paths)
+		}
+	}
+
+	// Two spare copies of 100 bytes and one of 40
+	savings, err := dedupSavings(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if savings != 240 {
+		t.Errorf("dedupSavings = %d, want 240", savings)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var errEditCancelled = errors.New("edit cancelled")
+
+const editablePlanHeader = `# Edit the new name (after the tab) on each line, then save and quit.
+# Delete a line to leave that file alone. Save an empty plan to cancel.
+`
+
+// Function to pick the folder new names in an editable plan are relative to:
+// the target folder when the run moves files there, otherwise the root
+func editTargetRoot(root string, opts Options) string {
+
+	if opts.TargetDir != "" {
+		return opts.TargetDir
+	}
+
+	return root
+}
+
+// Function to write the planned renames as editable "old<TAB>new" lines, old
+// names relative to root and new names relative to targetRoot
+func writeEditablePlan(w io.Writer, plan []RenameResult, root string, targetRoot string) error {
+
+	if _, err := io.WriteString(w, editablePlanHeader); err != nil {
+		return err
+	}
+
+	for _, result := range plan {
+		if result.Status != statusPlanned {
+			continue
+		}
+
+		oldRel, err := filepath.Rel(root, result.OldPath)
+		if err != nil {
+			return err
+		}
+		newRel, err := filepath.Rel(targetRoot, result.NewPath)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", filepath.ToSlash(oldRel), filepath.ToSlash(newRel)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Function to read an edited plan back. Only files from the original plan may appear,
+// new names must stay inside targetRoot, lines left pointing at the old name are
+// dropped, and an empty plan means cancel.
+func parseEditedPlan(r io.Reader, original []RenameResult, root string, targetRoot string) ([]RenameResult, error) {
+
+	planned := make(map[string]bool)
+	for _, result := range original {
+		if result.Status == statusPlanned {
+			planned[result.OldPath] = true
+		}
+	}
+
+	plan := []RenameResult{}
+	entries := 0
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		entries++
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 2 || fields[0] == "" || strings.TrimSpace(fields[1]) == "" {
+			return nil, fmt.Errorf("line %d: expected old<TAB>new", line)
+		}
+
+		oldPath := filepath.Join(root, filepath.FromSlash(fields[0]))
+		newPath := filepath.Join(targetRoot, filepath.FromSlash(fields[1]))
+		if !planned[oldPath] {
+			return nil, fmt.Errorf("line %d: %s was not in the plan", line, fields[0])
+		}
+		if !isWithin(targetRoot, newPath) {
+			return nil, fmt.Errorf("line %d: %s is outside %s", line, fields[1], targetRoot)
+		}
+		if oldPath == newPath {
+			continue
+		}
+
+		plan = append(plan, RenameResult{OldPath: oldPath, NewPath: newPath, Status: statusPlanned})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if entries == 0 {
+		return nil, errEditCancelled
+	}
+
+	return plan, nil
+}
+
+// Function to reject edited plans with repeated sources or targets, chains or swaps,
+// or targets that would overwrite existing files
+func validateEditedPlan(plan []RenameResult) error {
+
+	sources := make(map[string]bool)
+	for _, result := range plan {
+		if sources[result.OldPath] {
+			return fmt.Errorf("%s is listed more than once", result.OldPath)
+		}
+		sources[result.OldPath] = true
+	}
+
+	if conflicts := findDestinationConflicts(plan); len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+
+	for _, result := range plan {
+		if sources[result.NewPath] {
+			return fmt.Errorf("%s is both renamed and a rename target; chains and swaps are not supported", result.NewPath)
+		}
+		if _, err := os.Lstat(result.NewPath); err == nil {
+			return fmt.Errorf("cannot rename %s to %s: file exists", result.OldPath, result.NewPath)
+		}
+	}
+
+	return nil
+}
+
+// Function to pick the user's editor from $VISUAL or $EDITOR
+func editorCommand() []string {
+
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+
+	return []string{"vi"}
+}
+
+// Function to open the plan in the user's editor and return the edited, validated plan
+func editPlanInEditor(plan []RenameResult, root string, targetRoot string) ([]RenameResult, error) {
+
+	file, err := ioutil.TempFile("", "fileManager-plan-*.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	var before bytes.Buffer
+	if err := writeEditablePlan(&before, plan, root, targetRoot); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Write(before.Bytes()); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errEditCancelled
+	}
+
+	after, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	edited, err := parseEditedPlan(bytes.NewReader(after), plan, root, targetRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEditedPlan(edited); err != nil {
+		return nil, err
+	}
+
+	return edited, nil
+}
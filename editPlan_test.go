@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Function to build a planned entry under root from slash-separated names
+func plannedUnder(root string, oldName string, newName string) RenameResult {
+	return RenameResult{
+		OldPath: filepath.Join(root, filepath.FromSlash(oldName)),
+		NewPath: filepath.Join(root, filepath.FromSlash(newName)),
+		Status:  statusPlanned,
+	}
+}
+
+func TestWriteEditablePlan(t *testing.T) {
+
+	root := filepath.FromSlash("/photos")
+	plan := []RenameResult{
+		plannedUnder(root, "a.txt", "a.md"),
+		{OldPath: filepath.Join(root, "skip.txt"), Status: statusSkipped},
+		plannedUnder(root, "sub/b.txt", "sub/b.md"),
+	}
+
+	var out strings.Builder
+	if err := writeEditablePlan(&out, plan, root, root); err != nil {
+		t.Fatal(err)
+	}
+	if want := editablePlanHeader + "a.txt\ta.md\nsub/b.txt\tsub/b.md\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestParseEditedPlan(t *testing.T) {
+
+	root := filepath.FromSlash("/photos")
+	original := []RenameResult{
+		plannedUnder(root, "a.txt", "a.md"),
+		plannedUnder(root, "b.txt", "b.md"),
+		plannedUnder(root, "c.txt", "c.md"),
+	}
+
+	edited := "# comment\n\na.txt\tbeach.md\r\nb.txt\tb.txt\n"
+	plan, err := parseEditedPlan(strings.NewReader(edited), original, root, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 || plan[0] != plannedUnder(root, "a.txt", "beach.md") {
+		t.Errorf("plan = %+v", plan)
+	}
+
+	bad := map[string]string{
+		"not in plan":   "z.txt\tz.md\n",
+		"outside root":  "a.txt\t../escape.md\n",
+		"no tab":        "a.txt a.md\n",
+		"empty new":     "a.txt\t \n",
+		"too many tabs": "a.txt\ta.md\textra\n",
+	}
+	for name, text := range bad {
+		if _, err := parseEditedPlan(strings.NewReader(text), original, root, root); err == nil {
+			t.Errorf("%s: accepted %q", name, text)
+		}
+	}
+
+	for _, text := range []string{"", editablePlanHeader, "# only comments\n\n"} {
+		if _, err := parseEditedPlan(strings.NewReader(text), original, root, root); err != errEditCancelled {
+			t.Errorf("empty plan %q gave %v, want errEditCancelled", text, err)
+		}
+	}
+}
+
+func TestEditablePlanWithTargetDir(t *testing.T) {
+
+	root := filepath.FromSlash("/photos")
+	target := filepath.FromSlash("/archive/out")
+	original := []RenameResult{{OldPath: filepath.Join(root, "a.txt"), NewPath: filepath.Join(target, "a.md"), Status: statusPlanned}}
+
+	var out strings.Builder
+	if err := writeEditablePlan(&out, original, root, target); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), "a.txt\ta.md\n") {
+		t.Fatalf("plan = %q", out.String())
+	}
+
+	plan, err := parseEditedPlan(strings.NewReader("a.txt\tbeach.md\n"), original, root, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 || plan[0].NewPath != filepath.Join(target, "beach.md") {
+		t.Errorf("plan = %+v", plan)
+	}
+}
+
+func TestValidateEditedPlan(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "", "b.txt": "", "exists.md": ""})
+
+	cases := map[string][]RenameResult{
+		"repeated source": {plannedUnder(dir, "a.txt", "x.md"), plannedUnder(dir, "a.txt", "y.md")},
+		"repeated target": {plannedUnder(dir, "a.txt", "x.md"), plannedUnder(dir, "b.txt", "x.md")},
+		"chain":           {plannedUnder(dir, "a.txt", "b.txt"), plannedUnder(dir, "b.txt", "c.md")},
+		"swap":            {plannedUnder(dir, "a.txt", "b.txt"), plannedUnder(dir, "b.txt", "a.txt")},
+		"existing target": {plannedUnder(dir, "a.txt", "exists.md")},
+	}
+	for name, plan := range cases {
+		if err := validateEditedPlan(plan); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	var conflictErr *ConflictError
+	if err := validateEditedPlan(cases["repeated target"]); !errors.As(err, &conflictErr) {
+		t.Errorf("repeated target gave %v, want a ConflictError", err)
+	}
+
+	if err := validateEditedPlan([]RenameResult{plannedUnder(dir, "a.txt", "new.md")}); err != nil {
+		t.Errorf("valid plan rejected: %v", err)
+	}
+}
+
+func TestEditPlanInEditorAppliesThroughOptions(t *testing.T) {
+
+	if runtime.GOOS != "linux" {
+		t.Skip("uses GNU sed as the editor")
+	}
+	if _, err := exec.LookPath("sed"); err != nil {
+		t.Skip("sed not available")
+	}
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b"})
+	t.Setenv("VISUAL", "sed -i -e s/a.md$/edited.md/")
+
+	opts := Options{DryRun: true, Staged: true, Manifest: &Manifest{}}
+	plan, err := changeFileExtensionsWithOptions("txt", "md", dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edited, err := editPlanInEditor(plan, dir, editTargetRoot(dir, opts))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts.DryRun = false
+	results, err := applyExtensionPlan(edited, dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countStatus(results, statusRenamed) != 2 {
+		t.Errorf("results = %+v", results)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"b.md", "edited.md"}) {
+		t.Errorf("files = %v", got)
+	}
+	if len(opts.Manifest.Entries) != 2 {
+		t.Errorf("manifest has %d entries, want 2", len(opts.Manifest.Entries))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.md")); !os.IsNotExist(err) {
+		t.Error("unedited name was used")
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Function to keep a folder at no more than maxPerDir files by spilling the
+// excess, in name order, into part_2, part_3, ... subfolders. The first
+// maxPerDir files stay where they are. Returns the folder each moved file went to.
+func enforceMaxPerDir(folderPath string, maxPerDir int) (map[string]string, error) {
+	return enforceMaxPerDirWithOptions(folderPath, maxPerDir, Options{})
+}
+
+// Function to enforce a per-folder limit, recording moves in opts.Manifest
+func enforceMaxPerDirWithOptions(folderPath string, maxPerDir int, opts Options) (map[string]string, error) {
+
+	if maxPerDir < 1 {
+		return nil, fmt.Errorf("maxPerDir must be at least 1, got %d", maxPerDir)
+	}
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string)
+	if len(files) <= maxPerDir {
+		return mapping, nil
+	}
+
+	part := 2
+	partCount := -1
+	for _, file := range files[maxPerDir:] {
+
+		// Overflow folders may already hold files from an earlier run
+		for partCount < 0 || partCount >= maxPerDir {
+			if partCount >= 0 {
+				part++
+			}
+			existing, err := listFiles(filepath.Join(folderPath, fmt.Sprintf("part_%d", part)))
+			if err != nil {
+				existing = nil
+			}
+			partCount = len(existing)
+		}
+
+		folder := fmt.Sprintf("part_%d", part)
+		src := filepath.Join(folderPath, file.Name())
+		dst, err := moveFile(src, filepath.Join(folderPath, folder))
+		if err != nil {
+			opts.Manifest.record(operationOrganize, src, filepath.Join(folderPath, folder, file.Name()), statusFailed)
+			return mapping, err
+		}
+		opts.Manifest.record(operationOrganize, src, dst, statusRenamed)
+
+		mapping[file.Name()] = folder
+		partCount++
+	}
+
+	return mapping, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnforceMaxPerDirJustOverLimit(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a": "", "b": "", "c": ""})
+
+	mapping, err := enforceMaxPerDir(dir, 3)
+	if err != nil || len(mapping) != 0 {
+		t.Fatalf("at the limit: mapping = %v, %v", mapping, err)
+	}
+
+	writeTestFiles(t, dir, map[string]string{"d": ""})
+	mapping, err = enforceMaxPerDir(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"d": "part_2"}; !reflect.DeepEqual(mapping, want) {
+		t.Errorf("one over: mapping = %v, want %v", mapping, want)
+	}
+
+	// Later overflow tops up part_2 before starting part_3
+	writeTestFiles(t, dir, map[string]string{"e": "", "f": "", "g": "", "h": ""})
+	mapping, err = enforceMaxPerDir(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"e": "part_2", "f": "part_2", "g": "part_3", "h": "part_3"}; !reflect.DeepEqual(mapping, want) {
+		t.Errorf("refill: mapping = %v, want %v", mapping, want)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a", "b", "c", "part_2/d", "part_2/e", "part_2/f", "part_3/g", "part_3/h"}) {
+		t.Errorf("files = %v", got)
+	}
+
+	if _, err := enforceMaxPerDir(dir, 0); err == nil {
+		t.Error("maxPerDir 0 was accepted")
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// EXIF tags we read
+const (
+	exifIFDPointerTag       = 0x8769
+	exifDateTimeOriginalTag = 0x9003
+)
+
+var errNoEXIFDate = errors.New("no EXIF DateTimeOriginal")
+
+// Function to read the EXIF DateTimeOriginal of a JPEG file
+func readEXIFDateTime(path string) (time.Time, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer file.Close()
+
+	tiff, err := findEXIFSegment(file)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return parseEXIFDateTime(tiff)
+}
+
+// Function to read the EXIF DateTimeOriginal of a JPEG file in an fs.FS
+func readEXIFDateTimeFS(fsys fs.FS, name string) (time.Time, error) {
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer file.Close()
+
+	tiff, err := findEXIFSegment(file)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return parseEXIFDateTime(tiff)
+}
+
+// Function to walk JPEG markers up to the image data and return the TIFF block of the Exif APP1 segment
+func findEXIFSegment(r io.Reader) ([]byte, error) {
+
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return nil, errNoEXIFDate
+	}
+
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil || header[0] != 0xFF {
+			return nil, errNoEXIFDate
+		}
+
+		marker := header[1]
+		length := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if marker == 0xDA || marker == 0xD9 || length < 0 {
+			return nil, errNoEXIFDate
+		}
+
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return nil, errNoEXIFDate
+		}
+
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], nil
+		}
+	}
+}
+
+// Function to find DateTimeOriginal inside a TIFF-structured EXIF block
+func parseEXIFDateTime(tiff []byte) (time.Time, error) {
+
+	if len(tiff) < 8 {
+		return time.Time{}, errNoEXIFDate
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return time.Time{}, errNoEXIFDate
+	}
+
+	exifIFD, ok := findIFDEntry(tiff, order, order.Uint32(tiff[4:]), exifIFDPointerTag)
+	if !ok {
+		return time.Time{}, errNoEXIFDate
+	}
+
+	entry, ok := findIFDEntry(tiff, order, order.Uint32(exifIFD[8:]), exifDateTimeOriginalTag)
+	if !ok {
+		return time.Time{}, errNoEXIFDate
+	}
+
+	// ASCII values longer than four bytes are stored at an offset
+	count := order.Uint32(entry[4:])
+	offset := order.Uint32(entry[8:])
+	if count < 19 || uint64(offset)+uint64(count) > uint64(len(tiff)) {
+		return time.Time{}, errNoEXIFDate
+	}
+
+	value := strings.TrimRight(string(tiff[offset:offset+count]), "\x00 ")
+	return time.ParseInLocation("2006:01:02 15:04:05", value, time.Local)
+}
+
+// Function to return the 12-byte entry for a tag in the IFD at offset
+func findIFDEntry(tiff []byte, order binary.ByteOrder, offset uint32, tag uint16) ([]byte, bool) {
+
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return nil, false
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		start := uint64(offset) + 2 + uint64(i)*12
+		if start+12 > uint64(len(tiff)) {
+			return nil, false
+		}
+
+		entry := tiff[start : start+12]
+		if order.Uint16(entry) == tag {
+			return entry, true
+		}
+	}
+
+	return nil, false
+}
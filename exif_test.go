@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// Function to build a minimal TIFF block with an Exif IFD holding DateTimeOriginal
+func buildEXIFTIFF(order binary.ByteOrder, date string) []byte {
+
+	var tiff bytes.Buffer
+	put16 := func(v uint16) { binary.Write(&tiff, order, v) }
+	put32 := func(v uint32) { binary.Write(&tiff, order, v) }
+
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	put16(42)
+	put32(8)
+
+	// IFD0 at 8: a single pointer to the Exif IFD at 26
+	put16(1)
+	put16(exifIFDPointerTag)
+	put16(4)
+	put32(1)
+	put32(26)
+	put32(0)
+
+	// Exif IFD at 26: DateTimeOriginal, an ASCII value stored at 44
+	value := date + "\x00"
+	put16(1)
+	put16(exifDateTimeOriginalTag)
+	put16(2)
+	put32(uint32(len(value)))
+	put32(44)
+	put32(0)
+	tiff.WriteString(value)
+
+	return tiff.Bytes()
+}
+
+// Function to wrap a TIFF block in a JPEG, after an APP0 segment the reader has to skip
+func buildEXIFJPEG(order binary.ByteOrder, date string) []byte {
+
+	var jpeg bytes.Buffer
+	jpeg.Write([]byte{0xFF, 0xD8})
+
+	app0 := []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")
+	jpeg.Write([]byte{0xFF, 0xE0})
+	binary.Write(&jpeg, binary.BigEndian, uint16(len(app0)+2))
+	jpeg.Write(app0)
+
+	app1 := append([]byte("Exif\x00\x00"), buildEXIFTIFF(order, date)...)
+	jpeg.Write([]byte{0xFF, 0xE1})
+	binary.Write(&jpeg, binary.BigEndian, uint16(len(app1)+2))
+	jpeg.Write(app1)
+
+	jpeg.Write([]byte{0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9})
+
+	return jpeg.Bytes()
+}
+
+func TestParseEXIFDateTimeByteOrders(t *testing.T) {
+
+	want := time.Date(2021, 7, 4, 10, 30, 0, 0, time.Local)
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		tiff, err := findEXIFSegment(bytes.NewReader(buildEXIFJPEG(order, "2021:07:04 10:30:00")))
+		if err != nil {
+			t.Fatalf("%v: %v", order, err)
+		}
+		got, err := parseEXIFDateTime(tiff)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("%v: parseEXIFDateTime = %v, %v, want %v", order, got, err, want)
+		}
+	}
+}
+
+func TestParseEXIFDateTimeRejectsBadData(t *testing.T) {
+
+	valid := buildEXIFJPEG(binary.LittleEndian, "2021:07:04 10:30:00")
+
+	// The Exif IFD pointer is at tiff offset 18; point it past the end
+	badPointer := buildEXIFTIFF(binary.LittleEndian, "2021:07:04 10:30:00")
+	binary.LittleEndian.PutUint32(badPointer[18:], 5000)
+
+	cases := map[string][]byte{
+		"not a jpeg":             []byte("GIF89a"),
+		"empty":                  nil,
+		"truncated":              valid[:len(valid)/2],
+		"no exif before scan":    {0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02},
+		"segment length too big": {0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 'E', 'x'},
+	}
+	for name, data := range cases {
+		tiff, err := findEXIFSegment(bytes.NewReader(data))
+		if err == nil {
+			_, err = parseEXIFDateTime(tiff)
+		}
+		if err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+
+	for name, tiff := range map[string][]byte{
+		"bad byte order": append([]byte("XX"), badPointer[2:]...),
+		"bad pointer":    badPointer,
+		"short":          badPointer[:6],
+		"bad date":       buildEXIFTIFF(binary.BigEndian, "sometime last summer"),
+	} {
+		if _, err := parseEXIFDateTime(tiff); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CountRange is an inclusive range of how many files a run is expected to match
+type CountRange struct {
+	Min int
+	Max int
+}
+
+func (r CountRange) String() string {
+
+	if r.Min == r.Max {
+		return fmt.Sprintf("exactly %d", r.Min)
+	}
+
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// Function to parse "42" or "40-45" into a CountRange
+func parseCountRange(s string) (*CountRange, error) {
+
+	parts := strings.SplitN(s, "-", 2)
+	min, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid count %q", s)
+	}
+
+	max := min
+	if len(parts) == 2 {
+		if max, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+			return nil, fmt.Errorf("invalid count %q", s)
+		}
+	}
+
+	if min < 0 || max < min {
+		return nil, fmt.Errorf("invalid count range %q", s)
+	}
+
+	return &CountRange{Min: min, Max: max}, nil
+}
+
+// Function to refuse a plan whose number of matched files is outside the expected range
+func checkExpectedCount(plan []RenameResult, expect *CountRange) error {
+
+	if expect == nil {
+		return nil
+	}
+
+	matched := countStatus(plan, statusPlanned)
+	if matched < expect.Min || matched > expect.Max {
+		return fmt.Errorf("matched %d files, expected %s; nothing was renamed", matched, expect)
+	}
+
+	return nil
+}
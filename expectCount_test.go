@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCountRange(t *testing.T) {
+
+	for input, want := range map[string]CountRange{"42": {42, 42}, "40-45": {40, 45}, " 3 - 5 ": {3, 5}, "0": {0, 0}} {
+		got, err := parseCountRange(input)
+		if err != nil || *got != want {
+			t.Errorf("parseCountRange(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "x", "5-3", "-1", "4-x"} {
+		if _, err := parseCountRange(input); err == nil {
+			t.Errorf("parseCountRange(%q) was accepted", input)
+		}
+	}
+}
+
+func TestExpectedCountGuardsRun(t *testing.T) {
+
+	files := map[string]string{"a.md": "", "b.md": "", "c.md": "", "d.txt": ""}
+
+	cases := []struct {
+		expect  CountRange
+		allowed bool
+	}{
+		{CountRange{3, 3}, true},
+		{CountRange{2, 4}, true},
+		{CountRange{4, 10}, false}, // too few
+		{CountRange{0, 2}, false},  // too many
+	}
+
+	for _, c := range cases {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, files)
+		expect := c.expect
+
+		_, err := changeFileExtensionsWithOptions("md", "txt", dir, Options{Expect: &expect})
+		if c.allowed {
+			if err != nil {
+				t.Errorf("expect %v: %v", expect, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), "matched 3 files, expected "+expect.String()) {
+			t.Errorf("expect %v: err = %v", expect, err)
+		}
+		if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.md", "b.md", "c.md", "d.txt"}) {
+			t.Errorf("expect %v: refused run changed files: %v", expect, got)
+		}
+	}
+}
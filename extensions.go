@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExtCount is how many files share one extension
+type ExtCount struct {
+	Ext   string `json:"ext"`
+	Count int    `json:"count"`
+}
+
+// Function to count files per lower-cased extension ("" for files without one)
+func extensionInventory(folderPath string, recursive bool) (map[string]int, error) {
+
+	snapshot, err := scanSnapshot(folderPath, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := make(map[string]int)
+	for _, entry := range snapshot.Files {
+		inventory[strings.ToLower(path.Ext(entry.Path))]++
+	}
+
+	return inventory, nil
+}
+
+// Function to rank an inventory by count descending, ties alphabetically
+func rankInventory(inventory map[string]int) []ExtCount {
+
+	ranked := make([]ExtCount, 0, len(inventory))
+	for ext, count := range inventory {
+		ranked = append(ranked, ExtCount{Ext: ext, Count: count})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Ext < ranked[j].Ext
+	})
+
+	return ranked
+}
+
+// Function to list a folder's extensions from most to least common
+func rankedExtensions(folderPath string) ([]ExtCount, error) {
+
+	inventory, err := extensionInventory(folderPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankInventory(inventory), nil
+}
+
+// Function to list the extensions of a whole tree from most to least common
+func rankedExtensionsRecursive(folderPath string) ([]ExtCount, error) {
+
+	inventory, err := extensionInventory(folderPath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankInventory(inventory), nil
+}
+
+// Extensions that mean the same format, mapped to the one we group them under
+var extensionAliases = map[string]string{
+	".jpeg":     ".jpg",
+	".jpe":      ".jpg",
+	".tif":      ".tiff",
+	".htm":      ".html",
+	".mpeg":     ".mpg",
+	".yml":      ".yaml",
+	".markdown": ".md",
+	".text":     ".txt",
+}
+
+// Function to lower-case an extension and collapse known aliases, so ".JPEG" becomes ".jpg"
+func canonicalExt(ext string) string {
+
+	ext = strings.ToLower(ext)
+	if canonical, ok := extensionAliases[ext]; ok {
+		return canonical
+	}
+
+	return ext
+}
+
+// Function to move files into one folder per extension, with aliases like .jpeg and .jpg sharing a folder
+func organizeByExtension(folderPath string) (map[string]string, error) {
+	return organizeByExtensionWithOptions(folderPath, Options{})
+}
+
+// Function to pick the folder organizeByExtension files name under, and the
+// name it gets there
+func extensionDestination(name string, canonicalize bool) (string, string) {
+
+	// A dotfile like ".bashrc" has no extension, only a leading dot
+	ext := filepath.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	folder := strings.TrimPrefix(canonicalExt(ext), ".")
+	if folder == "" {
+		folder = "other"
+	}
+
+	if canonicalize && ext != "" {
+		name = strings.TrimSuffix(name, ext) + canonicalExt(ext)
+	}
+
+	return folder, name
+}
+
+// Function to organize files by extension. With opts.CanonicalizeExt the files
+// are also renamed to the canonical extension; otherwise they keep their own.
+// Returns the folder each file went to.
+func organizeByExtensionWithOptions(folderPath string, opts Options) (map[string]string, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string)
+	for _, file := range files {
+
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		folder, name := extensionDestination(file.Name(), opts.CanonicalizeExt)
+
+		src := filepath.Join(folderPath, file.Name())
+		dst, err := moveFileAs(src, filepath.Join(folderPath, folder), name)
+		if err != nil {
+			opts.Manifest.record(operationOrganize, src, filepath.Join(folderPath, folder, name), statusFailed)
+			fmt.Printf("Failed to move %s to %s: %v\n", src, folder, err)
+			continue
+		}
+
+		opts.Manifest.record(operationOrganize, src, dst, statusRenamed)
+
+		fmt.Printf("Moved: %s -> %s\n", src, dst)
+		mapping[file.Name()] = folder
+	}
+
+	return mapping, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRankedExtensions(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a.jpg": "", "b.JPG": "", "c.jpg": "",
+		"d.png": "", "e.png": "",
+		"f.txt": "", "g.txt": "",
+		"README":    "",
+		"sub/h.gif": "", "sub/i.gif": "", "sub/j.gif": "", "sub/k.gif": "",
+	})
+
+	ranked, err := rankedExtensions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ExtCount{{".jpg", 3}, {".png", 2}, {".txt", 2}, {"", 1}}
+	if !reflect.DeepEqual(ranked, want) {
+		t.Errorf("rankedExtensions = %v, want %v", ranked, want)
+	}
+
+	ranked, err = rankedExtensionsRecursive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append([]ExtCount{{".gif", 4}}, want...)
+	if !reflect.DeepEqual(ranked, want) {
+		t.Errorf("rankedExtensionsRecursive = %v, want %v", ranked, want)
+	}
+}
+
+func TestRankInventoryTieBreak(t *testing.T) {
+
+	ranked := rankInventory(map[string]int{".zip": 2, ".avi": 2, ".mp3": 2, ".doc": 5})
+	want := []ExtCount{{".doc", 5}, {".avi", 2}, {".mp3", 2}, {".zip", 2}}
+	if !reflect.DeepEqual(ranked, want) {
+		t.Errorf("rankInventory = %v, want %v", ranked, want)
+	}
+}
+
+func TestOrganizeByExtensionGroupsAliases(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a.jpg": "", "b.jpeg": "", "c.JPG": "",
+		"page.htm": "", "site.html": "",
+		"README": "", ".bashrc": "", ".config.yml": "",
+	})
+
+	mapping, err := organizeByExtension(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"a.jpg": "jpg", "b.jpeg": "jpg", "c.JPG": "jpg",
+		"page.htm": "html", "site.html": "html",
+		"README": "other", ".bashrc": "other", ".config.yml": "yaml",
+	}
+	if !reflect.DeepEqual(mapping, want) {
+		t.Errorf("mapping = %v, want %v", mapping, want)
+	}
+	readTestFile(t, dir, "jpg/b.jpeg")
+	readTestFile(t, dir, "other/.bashrc")
+}
+
+func TestOrganizeByExtensionCanonicalize(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.jpg": "", "b.JPEG": "", ".bashrc": ""})
+
+	if _, err := organizeByExtensionWithOptions(dir, Options{CanonicalizeExt: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"jpg/a.jpg", "jpg/b.jpg", "other/.bashrc"}) {
+		t.Errorf("files = %v", got)
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// Function to detect a file's content type from its first bytes, along with
+// the extension those bytes call for and how sure that guess is, from 0 to 1.
+// Content with no recognisable signature gets "" and 0.
+func detectContentType(path string) (string, string, float64, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", 0, err
+	}
+	defer file.Close()
+
+	return sniffContentType(file)
+}
+
+// Function to detect the content type of a file in an fs.FS, as detectContentType does on disk
+func detectContentTypeFS(fsys fs.FS, name string) (string, string, float64, error) {
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", "", 0, err
+	}
+	defer file.Close()
+
+	return sniffContentType(file)
+}
+
+// Function to detect a content type from the first bytes read from r
+func sniffContentType(r io.Reader) (string, string, float64, error) {
+
+	// http.DetectContentType never looks past the first 512 bytes
+	buffer := make([]byte, 512)
+	n, err := r.Read(buffer)
+	if err != nil && n == 0 {
+		// Empty files have nothing to sniff
+		return "application/octet-stream", "", 0, nil
+	}
+
+	ext, confidence := sniffExtension(buffer[:n])
+
+	return http.DetectContentType(buffer[:n]), ext, confidence, nil
+}
+
+// Function to find a free path by adding _1, _2, ... before the extension
+func uniquePath(path string) string {
+	return uniquePathAvoiding(path, nil)
+}
+
+// Function to find a free path that is also not already claimed by a plan
+func uniquePathAvoiding(path string, claimed map[string]bool) string {
+	return uniqueRenameTarget("", path, claimed)
+}
+
+// Function to find a free path to rename src to, where src's own path counts
+// as free. Without that, a file that already got "name_1" on an earlier run
+// would move on to "name_2" every time the run is repeated.
+func uniqueRenameTarget(src string, path string, claimed map[string]bool) string {
+
+	free := func(candidate string) bool {
+		if candidate == src {
+			return true
+		}
+		_, err := os.Lstat(candidate)
+		return os.IsNotExist(err) && !claimed[candidate]
+	}
+
+	return nextFreePath(path, free)
+}
+
+// Function to return path, or the first of path_1, path_2, ... that free accepts
+func nextFreePath(path string, free func(string) bool) string {
+
+	if free(path) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", stem, i, ext)
+		if free(candidate) {
+			return candidate
+		}
+	}
+}
+
+// Function to move a file into a folder, creating the folder if needed
+func moveFile(src string, dstDir string) (string, error) {
+	return moveFileAs(src, dstDir, filepath.Base(src))
+}
+
+// Function to move a file into a folder under a new name, creating the folder if needed
+func moveFileAs(src string, dstDir string, name string) (string, error) {
+
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return "", err
+	}
+
+	dst := uniquePath(filepath.Join(dstDir, name))
+	if err := renameOrCopy(src, dst, Options{}); err != nil {
+		return "", err
+	}
+
+	return dst, nil
+}
+
+// phaseRename is swapped out to make one step of a two-phase rename fail
+var phaseRename = os.Rename
+
+// Function to rename old->new pairs through temporary names so that
+// pairs swapping or shifting names can't clobber each other. If any step
+// fails every file is put back under its old name.
+func renameInTwoPhases(mapping map[string]string) error {
+
+	type pending struct{ old, tmp, new string }
+	var moves []pending
+
+	for oldPath, newPath := range mapping {
+		if oldPath == newPath {
+			continue
+		}
+		tmp := uniquePath(filepath.Join(filepath.Dir(oldPath), "."+filepath.Base(oldPath)+".fmtmp"))
+		moves = append(moves, pending{oldPath, tmp, newPath})
+	}
+	sort.Slice(moves, func(i, j int) bool { return moves[i].old < moves[j].old })
+
+	// Phase one: move everything out of the way
+	for i, move := range moves {
+		if err := phaseRename(move.old, move.tmp); err != nil {
+			for _, done := range moves[:i] {
+				os.Rename(done.tmp, done.old)
+			}
+			return fmt.Errorf("failed to rename %s: %v", move.old, err)
+		}
+	}
+
+	// Phase two: move everything into place
+	for i, move := range moves {
+		if err := phaseRename(move.tmp, move.new); err != nil {
+			// A finished move may sit on another file's old name, so take
+			// every finished one back to its temporary name first
+			for j := i - 1; j >= 0; j-- {
+				os.Rename(moves[j].new, moves[j].tmp)
+			}
+			for _, back := range moves {
+				os.Rename(back.tmp, back.old)
+			}
+			return fmt.Errorf("failed to rename %s to %s: %v", move.old, move.new, err)
+		}
+	}
+
+	return nil
+}
+
+// Function to copy a file's contents, mode and modification time to a new path.
+// The destination must not exist yet.
+func copyFile(src string, dst string) error {
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// moveRename and moveCopy are swapped out to simulate cross-device moves and bad copies
+var (
+	moveRename = os.Rename
+	moveCopy   = copyFile
+)
+
+// Function to rename a file, falling back to copy and delete when src and dst
+// are on different filesystems. With opts.VerifyCopy the source is only removed
+// once the copy's sha256 matches; on a mismatch both files are left in place.
+// With opts.UseTrash a file at dst goes to the trash instead of being replaced.
+func renameOrCopy(src string, dst string, opts Options) error {
+
+	if err := removeOverwritten(src, dst, opts); err != nil {
+		return fmt.Errorf("moving %s to the trash before overwriting it: %v", dst, err)
+	}
+
+	err := moveRename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := moveCopy(src, dst); err != nil {
+		return err
+	}
+
+	if err := preserveCreationTime(src, dst); err != nil {
+		fmt.Printf("Warning: could not keep creation time of %s: %v\n", src, err)
+	}
+
+	if opts.VerifyCopy {
+		if err := verifySameContent(src, dst); err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(src)
+}
+
+// Function to check that two files have the same sha256
+func verifySameContent(a string, b string) error {
+
+	hashA, err := hashFile(a)
+	if err != nil {
+		return err
+	}
+	hashB, err := hashFile(b)
+	if err != nil {
+		return err
+	}
+
+	if hashA != hashB {
+		return fmt.Errorf("copy verification failed: %s and %s differ", a, b)
+	}
+
+	return nil
+}
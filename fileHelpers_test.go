@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Function to make renameOrCopy take its cross-device path for one test
+func simulateCrossDevice(t *testing.T) {
+	t.Helper()
+
+	moveRename = func(src string, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EXDEV}
+	}
+	t.Cleanup(func() { moveRename = os.Rename })
+}
+
+func TestRenameOrCopyAcrossDevices(t *testing.T) {
+
+	simulateCrossDevice(t)
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"src.txt": "payload"})
+	src, dst := filepath.Join(dir, "src.txt"), filepath.Join(dir, "dst.txt")
+	stamp := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	os.Chtimes(src, stamp, stamp)
+
+	if err := renameOrCopy(src, dst, Options{VerifyCopy: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Error("source was not removed after a verified copy")
+	}
+	if readTestFile(t, dir, "dst.txt") != "payload" {
+		t.Error("copy has the wrong content")
+	}
+	if info, err := os.Stat(dst); err != nil || !info.ModTime().Equal(stamp) {
+		t.Errorf("copy lost its mod time: %v", err)
+	}
+}
+
+func TestRenameOrCopyMismatchKeepsSource(t *testing.T) {
+
+	simulateCrossDevice(t)
+	moveCopy = func(src string, dst string) error {
+		return os.WriteFile(dst, []byte("corrupted"), 0644)
+	}
+	t.Cleanup(func() { moveCopy = copyFile })
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"src.txt": "payload"})
+	src, dst := filepath.Join(dir, "src.txt"), filepath.Join(dir, "dst.txt")
+
+	if err := renameOrCopy(src, dst, Options{VerifyCopy: true}); err == nil {
+		t.Fatal("mismatched copy was accepted")
+	}
+
+	if readTestFile(t, dir, "src.txt") != "payload" {
+		t.Error("source did not survive a failed verification")
+	}
+	if readTestFile(t, dir, "dst.txt") != "corrupted" {
+		t.Error("mismatched copy should be left for inspection")
+	}
+}
+
+func TestUniqueRenameTarget(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "", "a_1.txt": ""})
+	join := func(name string) string { return filepath.Join(dir, name) }
+
+	if got := uniquePath(join("a.txt")); got != join("a_2.txt") {
+		t.Errorf("uniquePath = %s", got)
+	}
+	if got := uniquePathAvoiding(join("b.txt"), map[string]bool{join("b.txt"): true}); got != join("b_1.txt") {
+		t.Errorf("uniquePathAvoiding = %s", got)
+	}
+
+	// A file that already took a suffix keeps it on a repeat run
+	if got := uniqueRenameTarget(join("a_1.txt"), join("a.txt"), nil); got != join("a_1.txt") {
+		t.Errorf("uniqueRenameTarget = %s", got)
+	}
+}
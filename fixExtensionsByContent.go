@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// magicPart is a run of bytes expected at a fixed offset
+type magicPart struct {
+	offset int
+	magic  string
+}
+
+// contentSignature maps magic bytes to an extension. Confidence reflects how
+// specific the magic is: long unique headers score near 1, short or shared
+// ones (ZIP is also docx, jar, apk...) score lower. Compatible lists the
+// other extensions that share the same bytes and are left alone.
+type contentSignature struct {
+	ext        string
+	confidence float64
+	parts      []magicPart
+	compatible []string
+}
+
+// Formats built on ZIP, on the ISO base media file format (an ftyp box at
+// offset 4) and on plain text all carry their real type only in the extension
+var (
+	zipFamily = []string{".docx", ".xlsx", ".pptx", ".odt", ".ods", ".odp", ".odg", ".epub",
+		".jar", ".war", ".ear", ".apk", ".aab", ".ipa", ".xpi", ".vsix", ".nupkg", ".whl", ".kmz", ".3mf", ".cbz"}
+	isoBMFFFamily = []string{".m4v", ".m4a", ".m4b", ".m4p", ".m4r", ".mov", ".qt", ".3gp", ".3g2",
+		".heic", ".heif", ".avif", ".f4v", ".mj2", ".jp2"}
+	textFamily = []string{"", ".txt", ".md", ".markdown", ".rst", ".csv", ".tsv", ".json", ".xml", ".yaml", ".yml",
+		".toml", ".ini", ".cfg", ".conf", ".env", ".log", ".html", ".htm", ".css", ".svg", ".tex", ".srt", ".vtt",
+		".go", ".mod", ".sum", ".py", ".rb", ".rs", ".c", ".h", ".cc", ".cpp", ".hpp", ".java", ".kt", ".swift",
+		".js", ".mjs", ".ts", ".tsx", ".jsx", ".php", ".pl", ".lua", ".sql", ".sh", ".bash", ".zsh", ".bat", ".ps1"}
+)
+
+var contentSignatures = []contentSignature{
+	{".png", 1.0, []magicPart{{0, "\x89PNG\r\n\x1a\n"}}, nil},
+	{".gif", 1.0, []magicPart{{0, "GIF87a"}}, nil},
+	{".gif", 1.0, []magicPart{{0, "GIF89a"}}, nil},
+	{".webp", 1.0, []magicPart{{0, "RIFF"}, {8, "WEBP"}}, nil},
+	{".wav", 1.0, []magicPart{{0, "RIFF"}, {8, "WAVE"}}, nil},
+	{".avi", 1.0, []magicPart{{0, "RIFF"}, {8, "AVI "}}, nil},
+	{".pdf", 0.95, []magicPart{{0, "%PDF-"}}, []string{".ai"}},
+	{".flac", 0.95, []magicPart{{0, "fLaC"}}, nil},
+	{".ogg", 0.9, []magicPart{{0, "OggS"}}, []string{".oga", ".ogv", ".opus", ".spx"}},
+	{".jpg", 0.9, []magicPart{{0, "\xff\xd8\xff"}}, []string{".jpe", ".jfif"}},
+	{".mp3", 0.9, []magicPart{{0, "ID3"}}, nil},
+	{".mp4", 0.8, []magicPart{{4, "ftyp"}}, isoBMFFFamily},
+	{".gz", 0.8, []magicPart{{0, "\x1f\x8b\x08"}}, []string{".tgz"}},
+	{".zip", 0.6, []magicPart{{0, "PK\x03\x04"}}, zipFamily},
+	{".bmp", 0.4, []magicPart{{0, "BM"}}, []string{".dib"}},
+}
+
+// Confidence given to files that only look like text
+const textConfidence = 0.3
+
+// Function to check a header against a signature
+func (signature contentSignature) matches(header []byte) bool {
+
+	for _, part := range signature.parts {
+		end := part.offset + len(part.magic)
+		if end > len(header) || string(header[part.offset:end]) != part.magic {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Function to guess the extension a file's first bytes call for, with a
+// confidence from 0 to 1. Unrecognised content returns "" and 0.
+func sniffExtension(header []byte) (string, float64) {
+
+	if len(header) == 0 {
+		return "", 0
+	}
+
+	for _, signature := range contentSignatures {
+		if signature.matches(header) {
+			return signature.ext, signature.confidence
+		}
+	}
+
+	// A cut-off multi-byte rune at the end of the header is still text
+	text := header
+	for i := 0; i < utf8.UTFMax && len(text) > 0 && !utf8.Valid(text); i++ {
+		text = text[:len(text)-1]
+	}
+	if len(text) > 0 && utf8.Valid(text) && bytes.IndexByte(text, 0) < 0 {
+		return ".txt", textConfidence
+	}
+
+	return "", 0
+}
+
+// Function to tell whether a file's current extension already fits the
+// detected one, either exactly or as another format sharing its bytes
+func extensionFits(current string, detected string) bool {
+
+	current = canonicalExt(current)
+	if current == detected {
+		return true
+	}
+
+	compatible := textFamily
+	if detected != ".txt" {
+		compatible = nil
+		for _, signature := range contentSignatures {
+			if signature.ext == detected {
+				compatible = signature.compatible
+				break
+			}
+		}
+	}
+
+	for _, ext := range compatible {
+		if current == ext {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Function to give files the extension their content calls for. Files the
+// detector is less than minConfidence sure about are left alone and reported
+// as skipped. Files whose extension is compatible with their content, such as
+// a .docx holding ZIP bytes or a .go file holding text, are left alone too,
+// as are dotfiles like .bashrc.
+func fixExtensionsByContent(folderPath string, minConfidence float64) ([]RenameResult, error) {
+	return fixExtensionsByContentWithOptions(folderPath, minConfidence, Options{})
+}
+
+// Function to fix extensions by content, recording renames in opts.Manifest
+func fixExtensionsByContentWithOptions(folderPath string, minConfidence float64, opts Options) ([]RenameResult, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[string]bool)
+	results := []RenameResult{}
+	for _, file := range files {
+
+		// A dotfile like ".bashrc" has no extension to fix, only a leading dot
+		if filepath.Ext(file.Name()) == file.Name() {
+			continue
+		}
+
+		oldPath := filepath.Join(folderPath, file.Name())
+		result := RenameResult{OldPath: oldPath, Size: file.Size()}
+
+		_, ext, confidence, err := detectContentType(oldPath)
+		switch {
+		case err != nil:
+			result.Status = statusFailed
+			result.Reason = err.Error()
+			results = append(results, result)
+			continue
+		case ext == "":
+			result.Status = statusSkipped
+			result.Reason = "content not recognised"
+			results = append(results, result)
+			continue
+		case extensionFits(filepath.Ext(file.Name()), ext):
+			continue
+		case confidence < minConfidence:
+			result.Status = statusSkipped
+			result.Reason = fmt.Sprintf("looks like %s but confidence %.2f is below %.2f", ext, confidence, minConfidence)
+			results = append(results, result)
+			continue
+		}
+
+		stem := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		newPath := uniqueRenameTarget(oldPath, filepath.Join(folderPath, stem+ext), claimed)
+		claimed[newPath] = true
+		result.NewPath = newPath
+
+		if err := os.Rename(oldPath, newPath); err != nil {
+			result.Status = statusFailed
+			result.Reason = err.Error()
+		} else {
+			result.Status = statusRenamed
+		}
+		results = append(results, result)
+	}
+
+	opts.Manifest.recordResults(operationRename, results)
+
+	return results, nil
+}
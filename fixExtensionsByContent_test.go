@@ -0,0 +1,137 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Sample bytes, from clearly typed to ambiguous
+var (
+	pngBytes  = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	zipBytes  = "PK\x03\x04\x14\x00\x00\x00\x08\x00"
+	ftypBytes = "\x00\x00\x00\x18ftypqt  \x00\x00\x02\x00"
+	bmpBytes  = "BMjunk that might not be a bitmap\x00\x01"
+	textBytes = "just some words\nand a second line\n"
+)
+
+func TestSniffExtension(t *testing.T) {
+
+	cases := []struct {
+		header     string
+		ext        string
+		confidence float64
+	}{
+		{pngBytes, ".png", 1.0},
+		{zipBytes, ".zip", 0.6},
+		{ftypBytes, ".mp4", 0.8},
+		{bmpBytes, ".bmp", 0.4},
+		{textBytes, ".txt", textConfidence},
+		{"caf\xc3", ".txt", textConfidence},
+		{"\x00\x01\x02\x03", "", 0},
+		{"", "", 0},
+	}
+
+	for _, c := range cases {
+		ext, confidence := sniffExtension([]byte(c.header))
+		if ext != c.ext || confidence != c.confidence {
+			t.Errorf("sniffExtension(%q) = %q, %v, want %q, %v", c.header, ext, confidence, c.ext, c.confidence)
+		}
+	}
+}
+
+func TestExtensionFits(t *testing.T) {
+
+	for _, c := range []struct {
+		current, detected string
+		fits              bool
+	}{
+		{".JPEG", ".jpg", true},
+		{".docx", ".zip", true},
+		{".jar", ".zip", true},
+		{".mov", ".mp4", true},
+		{".m4a", ".mp4", true},
+		{".go", ".txt", true},
+		{"", ".txt", true},
+		{".dat", ".zip", false},
+		{".png", ".jpg", false},
+		{".docx", ".mp4", false},
+		{".go", ".zip", false},
+	} {
+		if got := extensionFits(c.current, c.detected); got != c.fits {
+			t.Errorf("extensionFits(%q, %q) = %v, want %v", c.current, c.detected, got, c.fits)
+		}
+	}
+}
+
+func TestFixExtensionsByContentThreshold(t *testing.T) {
+
+	files := map[string]string{
+		"image.dat":   pngBytes,
+		"archive.dat": zipBytes,
+		"bitmap.dat":  bmpBytes,
+		"notes.dat":   textBytes,
+		"random.dat":  "\x00\x01\x02\x03",
+		// Compatible extensions are never rewritten, whatever the threshold
+		"main.go":     "package main\n",
+		"README":      textBytes,
+		"report.docx": zipBytes,
+		"clip.mov":    ftypBytes,
+		"photo.JPG":   "\xff\xd8\xff\xe0",
+		// Dotfiles have no extension to fix
+		".bashrc":    textBytes,
+		".gitignore": textBytes,
+	}
+
+	cases := []struct {
+		minConfidence float64
+		renamed       []string
+	}{
+		{0, []string{"archive.zip", "bitmap.bmp", "image.png", "notes.txt"}},
+		{0.5, []string{"archive.zip", "image.png"}},
+		{0.7, []string{"image.png"}},
+		{1.01, nil},
+	}
+
+	for _, c := range cases {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, files)
+
+		results, err := fixExtensionsByContent(dir, c.minConfidence)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var renamed []string
+		for _, result := range results {
+			name := filepath.Base(result.OldPath)
+			switch result.Status {
+			case statusRenamed:
+				renamed = append(renamed, filepath.Base(result.NewPath))
+			case statusSkipped:
+				if name == "random.dat" && result.Reason != "content not recognised" {
+					t.Errorf("min %v: random.dat skipped for %q", c.minConfidence, result.Reason)
+				}
+				if name != "random.dat" && !strings.Contains(result.Reason, "below") {
+					t.Errorf("min %v: %s skipped for %q", c.minConfidence, name, result.Reason)
+				}
+			default:
+				t.Errorf("min %v: %s %s: %s", c.minConfidence, name, result.Status, result.Reason)
+			}
+			for _, compatible := range []string{"main.go", "README", "report.docx", "clip.mov", "photo.JPG", ".bashrc", ".gitignore"} {
+				if name == compatible {
+					t.Errorf("min %v: %s with a compatible extension was reported: %+v", c.minConfidence, name, result)
+				}
+			}
+		}
+
+		if !equalStrings(renamed, c.renamed) {
+			t.Errorf("min %v: renamed %v, want %v", c.minConfidence, renamed, c.renamed)
+		}
+		for _, dotfile := range []string{".bashrc", ".gitignore"} {
+			if got := readTestFile(t, dir, dotfile); got != textBytes {
+				t.Errorf("min %v: %s = %q", c.minConfidence, dotfile, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Function to write results in Renamed / Skipped / Failed sections with a count per section
+func writeResultsGrouped(w io.Writer, results []RenameResult) error {
+
+	sections := []struct {
+		title  string
+		status string
+	}{
+		{"Planned", statusPlanned},
+		{"Renamed", statusRenamed},
+		{"Skipped", statusSkipped},
+		{"Failed", statusFailed},
+	}
+
+	for _, section := range sections {
+
+		count := countStatus(results, section.status)
+		if count == 0 && section.status == statusPlanned {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s (%d)\n", section.title, count); err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			if result.Status != section.status {
+				continue
+			}
+
+			var err error
+			switch section.status {
+			case statusSkipped, statusFailed:
+				_, err = fmt.Fprintf(w, "  %s: %s\n", displayPath(result.OldPath), result.Reason)
+			default:
+				_, err = fmt.Fprintf(w, "  %s -> %s\n", result.OldPath, result.NewPath)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Function to show a placeholder for results that never had a file, like bad CSV rows
+func displayPath(path string) string {
+
+	if path == "" {
+		return "(no file)"
+	}
+
+	return path
+}
+
+// Function to write current and new names to two files whose lines match up,
+// for reviewing a plan in a side-by-side diff tool. Entries that won't be
+// renamed keep their current name on both sides.
+func writeBeforeAfter(plan []RenameResult, beforePath string, afterPath string) error {
+
+	pairs := make([]RenameResult, 0, len(plan))
+	for _, result := range plan {
+		if result.OldPath != "" {
+			pairs = append(pairs, result)
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].OldPath < pairs[j].OldPath
+	})
+
+	var before, after strings.Builder
+	for _, pair := range pairs {
+
+		newPath := pair.NewPath
+		if pair.Status == statusSkipped || pair.Status == statusFailed || newPath == "" {
+			newPath = pair.OldPath
+		}
+
+		before.WriteString(pair.OldPath + "\n")
+		after.WriteString(newPath + "\n")
+	}
+
+	if err := ioutil.WriteFile(beforePath, []byte(before.String()), 0644); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(afterPath, []byte(after.String()), 0644)
+}
+
+// Escapes for DOT string literals
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+
+// Function to quote a string as a DOT identifier
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// Function to write a plan as a Graphviz DOT graph with an edge from each
+// source to its destination, labelled with the entry's status
+func writePlanDOT(plan []RenameResult, w io.Writer) error {
+
+	if _, err := fmt.Fprintln(w, "digraph plan {\n  rankdir=LR;\n  node [shape=box];"); err != nil {
+		return err
+	}
+
+	for _, result := range plan {
+		if result.OldPath == "" || result.NewPath == "" {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "  %s -> %s [label=%s];\n",
+			dotQuote(result.OldPath), dotQuote(result.NewPath), dotQuote(result.Status)); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteResultsGrouped(t *testing.T) {
+
+	results := []RenameResult{
+		{OldPath: "a.txt", NewPath: "a.md", Status: statusRenamed},
+		{OldPath: "b.txt", Status: statusSkipped, Reason: "limit of 1 reached"},
+		{OldPath: "c.txt", NewPath: "c.md", Status: statusRenamed},
+		{Status: statusFailed, Reason: "row 3: bad row"},
+	}
+
+	var out strings.Builder
+	if err := writeResultsGrouped(&out, results); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Renamed (2)
+  a.txt -> a.md
+  c.txt -> c.md
+
+Skipped (1)
+  b.txt: limit of 1 reached
+
+Failed (1)
+  (no file): row 3: bad row
+
+`
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	// Dry runs get a Planned section first
+	out.Reset()
+	writeResultsGrouped(&out, []RenameResult{{OldPath: "x", NewPath: "y", Status: statusPlanned}})
+	if !strings.HasPrefix(out.String(), "Planned (1)\n  x -> y\n") {
+		t.Errorf("dry run output:\n%s", out.String())
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteResultsGroupedReportsWriteErrors(t *testing.T) {
+
+	if err := writeResultsGrouped(failingWriter{}, []RenameResult{{Status: statusRenamed}}); err == nil {
+		t.Error("write error was swallowed")
+	}
+}
+
+func TestWriteBeforeAfterAlignsLines(t *testing.T) {
+
+	plan := []RenameResult{
+		{OldPath: "c.txt", NewPath: "c.md", Status: statusPlanned},
+		{OldPath: "a.txt", NewPath: "a.md", Status: statusRenamed},
+		{OldPath: "b.txt", NewPath: "b.md", Status: statusSkipped, Reason: "limit"},
+		{OldPath: "d.txt", NewPath: "d.md", Status: statusFailed, Reason: "denied"},
+		{Status: statusFailed, Reason: "bad csv row"},
+	}
+
+	dir := t.TempDir()
+	before, after := filepath.Join(dir, "before.txt"), filepath.Join(dir, "after.txt")
+	if err := writeBeforeAfter(plan, before, after); err != nil {
+		t.Fatal(err)
+	}
+
+	beforeLines := strings.Split(readTestFile(t, dir, "before.txt"), "\n")
+	afterLines := strings.Split(readTestFile(t, dir, "after.txt"), "\n")
+	if want := []string{"a.txt", "b.txt", "c.txt", "d.txt", ""}; !equalStrings(beforeLines, want) {
+		t.Errorf("before = %q, want %q", beforeLines, want)
+	}
+	if want := []string{"a.md", "b.txt", "c.md", "d.txt", ""}; !equalStrings(afterLines, want) {
+		t.Errorf("after = %q, want %q", afterLines, want)
+	}
+}
+
+func TestWritePlanDOT(t *testing.T) {
+
+	plan := []RenameResult{
+		{OldPath: "a.md", NewPath: "a.txt", Status: statusPlanned},
+		{OldPath: `say "hi".md`, NewPath: `dir\say "hi".txt`, Status: statusSkipped},
+		{OldPath: "bad,row", Status: statusFailed},
+	}
+
+	var out strings.Builder
+	if err := writePlanDOT(plan, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `digraph plan {
+  rankdir=LR;
+  node [shape=box];
+  "a.md" -> "a.txt" [label="planned"];
+  "say \"hi\".md" -> "dir\\say \"hi\".txt" [label="skipped"];
+}
+`
+	if out.String() != want {
+		t.Errorf("DOT =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	if err := writePlanDOT(plan, failingWriter{}); err == nil {
+		t.Error("write error was swallowed")
+	}
+}
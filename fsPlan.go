@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Function to capture the files of an fs.FS into a snapshot. Paths are slash
+// separated and relative to the FS root, and the snapshot has no Root.
+func scanFS(fsys fs.FS, recursive bool) (*Snapshot, error) {
+
+	snapshot := &Snapshot{Files: []SnapshotEntry{}}
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if name != "." && !recursive {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		snapshot.Files = append(snapshot.Files, SnapshotEntry{
+			Path:    name,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// Function to plan an extension change against an fs.FS without writing
+// anything. Paths in the plan are slash separated and relative to the FS root,
+// ready for writePlanJSON and applyPlanAt. Targets that already exist in the
+// FS are planned as skipped.
+func planFromFS(oldExt string, newExt string, fsys fs.FS, opts Options) ([]RenameResult, error) {
+
+	snapshot, err := scanFS(fsys, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planExtensionChange(oldExt, newExt, snapshot)
+	if opts.TargetDir != "" {
+		retargetPlan(plan, opts.TargetDir)
+	}
+
+	for i := range plan {
+		plan[i].OldPath = filepath.ToSlash(plan[i].OldPath)
+		plan[i].NewPath = filepath.ToSlash(plan[i].NewPath)
+		if !fs.ValidPath(plan[i].NewPath) {
+			return nil, fmt.Errorf("target %s is outside the planned tree", plan[i].NewPath)
+		}
+		if _, err := fs.Stat(fsys, plan[i].NewPath); err == nil {
+			plan[i].Status = statusSkipped
+			plan[i].Reason = "target already exists"
+		}
+	}
+
+	if conflicts := findDestinationConflicts(plan); len(conflicts) > 0 {
+		return plan, &ConflictError{Conflicts: conflicts}
+	}
+
+	if err := checkExpectedCount(plan, opts.Expect); err != nil {
+		return plan, err
+	}
+
+	return plan, nil
+}
+
+// Function to plan moving the files at the top of an fs.FS into the folder
+// destinationFor picks for each, under the name it picks. A name already
+// taken in the FS or earlier in the plan gets a _1, _2, ... suffix, as the
+// organize operations give it on disk.
+func planOrganizeFS(fsys fs.FS, destinationFor func(info fs.FileInfo) (string, string), opts Options) ([]RenameResult, error) {
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[string]bool)
+	free := func(candidate string) bool {
+		if claimed[candidate] {
+			return false
+		}
+		_, err := fs.Stat(fsys, candidate)
+		return errors.Is(err, fs.ErrNotExist)
+	}
+
+	plan := []RenameResult{}
+	for _, entry := range entries {
+
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		folder, name := destinationFor(info)
+		newPath := nextFreePath(path.Join(folder, name), free)
+		claimed[newPath] = true
+
+		plan = append(plan, RenameResult{
+			OldPath: entry.Name(),
+			NewPath: newPath,
+			Status:  statusPlanned,
+			Size:    info.Size(),
+		})
+	}
+
+	if err := checkExpectedCount(plan, opts.Expect); err != nil {
+		return plan, err
+	}
+
+	return plan, nil
+}
+
+// Function to plan organizeByExtension against an fs.FS
+func planOrganizeByExtensionFS(fsys fs.FS, opts Options) ([]RenameResult, error) {
+	return planOrganizeFS(fsys, func(info fs.FileInfo) (string, string) {
+		return extensionDestination(info.Name(), opts.CanonicalizeExt)
+	}, opts)
+}
+
+// Function to plan organizeByMediaCategory against an fs.FS
+func planOrganizeByMediaCategoryFS(fsys fs.FS, opts Options) ([]RenameResult, error) {
+	return planOrganizeFS(fsys, func(info fs.FileInfo) (string, string) {
+		return fileMediaCategoryFS(fsys, info.Name()), info.Name()
+	}, opts)
+}
+
+// Function to plan organizeByDate against an fs.FS
+func planOrganizeByDateFS(fsys fs.FS, source DateSource, opts Options) ([]RenameResult, error) {
+	return planOrganizeFS(fsys, func(info fs.FileInfo) (string, string) {
+		date := info.ModTime()
+		if source == DateFromEXIF {
+			if taken, err := readEXIFDateTimeFS(fsys, info.Name()); err == nil {
+				date = taken
+			}
+		}
+		return dateFolder(date), info.Name()
+	}, opts)
+}
+
+// Function to write a plan as JSON for another process to apply
+func writePlanJSON(w io.Writer, plan []RenameResult) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(plan)
+}
+
+// Function to read a plan written by writePlanJSON. Every path must be
+// relative and stay inside the tree, so a plan can't reach outside its root.
+func readPlanJSON(r io.Reader) ([]RenameResult, error) {
+
+	var plan []RenameResult
+	if err := json.NewDecoder(r).Decode(&plan); err != nil {
+		return nil, err
+	}
+
+	for _, result := range plan {
+		for _, name := range []string{result.OldPath, result.NewPath} {
+			if name != "" && (!fs.ValidPath(name) || path.Clean(name) != name) {
+				return nil, fmt.Errorf("invalid path %q in plan", name)
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// Function to carry out a plan made over an fs.FS against the files under
+// root. The disk may not match the FS the plan was made from, so destinations
+// are checked again here: a target that already exists is skipped rather than
+// overwritten, and a plan sending two files to one place is refused.
+func applyPlanAt(plan []RenameResult, root string, opts Options) ([]RenameResult, error) {
+
+	joined := make([]RenameResult, len(plan))
+	for i, result := range plan {
+		joined[i] = result
+		joined[i].OldPath = filepath.Join(root, filepath.FromSlash(result.OldPath))
+		joined[i].NewPath = filepath.Join(root, filepath.FromSlash(result.NewPath))
+	}
+
+	if conflicts := findDestinationConflicts(joined); len(conflicts) > 0 {
+		return joined, &ConflictError{Conflicts: conflicts}
+	}
+
+	existing := make(map[string]bool)
+	for _, conflict := range findExistingDestinations(joined) {
+		existing[conflict.Sources[0]] = true
+	}
+
+	for i := range joined {
+		result := &joined[i]
+		if result.Status != statusPlanned {
+			continue
+		}
+		if existing[result.OldPath] {
+			result.Status = statusSkipped
+			result.Reason = "target already exists"
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(result.NewPath), 0755); err != nil {
+			result.Status = statusFailed
+			result.Reason = err.Error()
+		}
+	}
+
+	return applyPlan(joined, root, opts), nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestPlanFromMapFS(t *testing.T) {
+
+	stamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"a.md":          {Data: []byte("a"), ModTime: stamp},
+		"b.md":          {Data: []byte("b"), ModTime: stamp},
+		"b.txt":         {Data: []byte("taken"), ModTime: stamp},
+		"keep.go":       {Data: []byte("package main"), ModTime: stamp},
+		"docs/guide.md": {Data: []byte("guide"), ModTime: stamp},
+	}
+
+	plan, err := planFromFS("md", "txt", fsys, Options{Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := writePlanJSON(&out, plan); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[
+  {
+    "oldPath": "a.md",
+    "newPath": "a.txt",
+    "status": "planned"
+  },
+  {
+    "oldPath": "b.md",
+    "newPath": "b.txt",
+    "status": "skipped",
+    "reason": "target already exists"
+  },
+  {
+    "oldPath": "docs/guide.md",
+    "newPath": "docs/guide.txt",
+    "status": "planned"
+  }
+]
+`
+	if out.String() != want {
+		t.Errorf("plan JSON =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// The serialized plan reads back and applies to a real copy of the tree
+	readBack, err := readPlanJSON(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.md": "a", "b.md": "b", "b.txt": "taken", "keep.go": "", "docs/guide.md": "guide"})
+	results, err := applyPlanAt(readBack, dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countStatus(results, statusRenamed) != 2 {
+		t.Errorf("results = %+v", results)
+	}
+	wantFiles := []string{"a.txt", "b.md", "b.txt", "docs/guide.txt", "keep.go"}
+	if got := listTestFiles(t, dir); !equalStrings(got, wantFiles) {
+		t.Errorf("files = %v, want %v", got, wantFiles)
+	}
+}
+
+func TestPlanFromMapFSRejects(t *testing.T) {
+
+	fsys := fstest.MapFS{"a/x.jpeg": {}, "b/x.jpeg": {}}
+
+	_, err := planFromFS("jpeg", "jpg", fsys, Options{Recursive: true, TargetDir: "out"})
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) != 1 {
+		t.Errorf("err = %v, want one conflict on out/x.jpg", err)
+	}
+
+	if _, err := planFromFS("jpeg", "jpg", fsys, Options{Recursive: true, TargetDir: "../out"}); err == nil {
+		t.Error("a target outside the tree was planned")
+	}
+
+	for _, plan := range []string{
+		`[{"oldPath": "../escape.md", "newPath": "a.txt", "status": "planned"}]`,
+		`[{"oldPath": "/etc/passwd", "newPath": "a.txt", "status": "planned"}]`,
+		`[{"oldPath": "a.md", "newPath": "./a.txt", "status": "planned"}]`,
+	} {
+		if _, err := readPlanJSON(strings.NewReader(plan)); err == nil {
+			t.Errorf("readPlanJSON accepted %s", plan)
+		}
+	}
+}
+
+func TestApplyPlanAtChecksTheDisk(t *testing.T) {
+
+	plan, err := planFromFS("md", "txt", fstest.MapFS{"a.md": {Data: []byte("a")}, "b.md": {Data: []byte("b")}}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The copy on disk already has an a.txt the FS didn't
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.md": "a", "a.txt": "precious", "b.md": "b"})
+	results, err := applyPlanAt(plan, dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Status != statusSkipped || results[0].Reason != "target already exists" || results[1].Status != statusRenamed {
+		t.Errorf("results = %+v", results)
+	}
+	if got := readTestFile(t, dir, "a.txt"); got != "precious" {
+		t.Errorf("a.txt = %q", got)
+	}
+
+	// A plan sending two files to one place is refused outright
+	twice := []RenameResult{
+		{OldPath: "a.md", NewPath: "c.txt", Status: statusPlanned},
+		{OldPath: "a.txt", NewPath: "c.txt", Status: statusPlanned},
+	}
+	_, err = applyPlanAt(twice, dir, Options{})
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Errorf("err = %v, want a ConflictError", err)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.md", "a.txt", "b.txt"}) {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestPlanOrganizeFromMapFS(t *testing.T) {
+
+	may := time.Date(2023, 5, 17, 12, 0, 0, 0, time.UTC)
+	june := time.Date(2023, 6, 2, 12, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"a.JPEG":     {Data: []byte(pngBytes), ModTime: may},
+		"a.jpg":      {Data: []byte(pngBytes), ModTime: may},
+		"notes.txt":  {Data: []byte(textBytes), ModTime: june},
+		"song.dat":   {Data: []byte("ID3\x03\x00"), ModTime: june},
+		".bashrc":    {Data: []byte(textBytes), ModTime: june},
+		"jpg/b.jpg":  {Data: []byte(pngBytes), ModTime: may},
+		"2023/05/17": {Mode: fs.ModeDir},
+	}
+
+	plans := []struct {
+		name string
+		plan func() ([]RenameResult, error)
+		want []string
+	}{
+		{"extension", func() ([]RenameResult, error) {
+			return planOrganizeByExtensionFS(fsys, Options{CanonicalizeExt: true})
+		}, []string{".bashrc -> other/.bashrc", "a.JPEG -> jpg/a.jpg", "a.jpg -> jpg/a_1.jpg", "notes.txt -> txt/notes.txt", "song.dat -> dat/song.dat"}},
+		{"media", func() ([]RenameResult, error) {
+			return planOrganizeByMediaCategoryFS(fsys, Options{})
+		}, []string{".bashrc -> Documents/.bashrc", "a.JPEG -> Images/a.JPEG", "a.jpg -> Images/a.jpg", "notes.txt -> Documents/notes.txt", "song.dat -> Audio/song.dat"}},
+		{"date", func() ([]RenameResult, error) {
+			return planOrganizeByDateFS(fsys, DateFromModTime, Options{})
+		}, []string{".bashrc -> 2023/06/02/.bashrc", "a.JPEG -> 2023/05/17/a.JPEG", "a.jpg -> 2023/05/17/a.jpg", "notes.txt -> 2023/06/02/notes.txt", "song.dat -> 2023/06/02/song.dat"}},
+	}
+
+	for _, p := range plans {
+		plan, err := p.plan()
+		if err != nil {
+			t.Fatalf("%s: %v", p.name, err)
+		}
+		var got []string
+		for _, result := range plan {
+			if result.Status != statusPlanned {
+				t.Errorf("%s: %+v", p.name, result)
+			}
+			got = append(got, result.OldPath+" -> "+result.NewPath)
+		}
+		if !equalStrings(got, p.want) {
+			t.Errorf("%s plan = %v, want %v", p.name, got, p.want)
+		}
+	}
+
+	// An organize plan applies to a real copy, creating the folders it needs
+	plan, err := planOrganizeByExtensionFS(fsys, Options{CanonicalizeExt: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.JPEG": "A", "a.jpg": "a", "notes.txt": "n", "song.dat": "s", ".bashrc": "b", "jpg/b.jpg": "B"})
+	results, err := applyPlanAt(plan, dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := countStatus(results, statusRenamed); got != 5 {
+		t.Errorf("renamed %d, want 5: %+v", got, results)
+	}
+	want := []string{"dat/song.dat", "jpg/a.jpg", "jpg/a_1.jpg", "jpg/b.jpg", "other/.bashrc", "txt/notes.txt"}
+	if got := listTestFiles(t, dir); !equalStrings(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if got := readTestFile(t, dir, "jpg/a.jpg"); got != "A" {
+		t.Errorf("jpg/a.jpg = %q", got)
+	}
+
+	if _, err := planOrganizeByExtensionFS(fsys, Options{Expect: &CountRange{Min: 2, Max: 2}}); err == nil {
+		t.Error("an unexpected count was planned")
+	}
+}
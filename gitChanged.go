@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Function to parse `git status --porcelain -z` output into changed or untracked paths.
+// Deleted files are left out since there is nothing left on disk to process.
+func parseGitPorcelain(out []byte) []string {
+
+	var paths []string
+	fields := bytes.Split(out, []byte{0})
+	for i := 0; i < len(fields); i++ {
+
+		field := string(fields[i])
+		if len(field) < 4 {
+			continue
+		}
+
+		status, path := field[:2], field[3:]
+
+		// Renames and copies are followed by a separate field holding the old path
+		if status[0] == 'R' || status[0] == 'C' {
+			i++
+		}
+
+		if status[0] == 'D' || status[1] == 'D' {
+			continue
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+// Function to list the files git reports as changed or untracked under folderPath, as absolute paths
+func gitChangedFiles(folderPath string) (map[string]bool, error) {
+
+	top, err := exec.Command("git", "-C", folderPath, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s is not inside a git repository", folderPath)
+	}
+	repoRoot := strings.TrimSpace(string(top))
+
+	out, err := exec.Command("git", "-C", repoRoot, "status", "--porcelain", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %v", err)
+	}
+
+	changed := make(map[string]bool)
+	for _, path := range parseGitPorcelain(out) {
+		changed[filepath.Join(repoRoot, filepath.FromSlash(path))] = true
+	}
+
+	return changed, nil
+}
+
+// Function to drop snapshot entries git doesn't report as changed or untracked
+func filterSnapshotToGitChanged(snapshot *Snapshot) error {
+
+	changed, err := gitChangedFiles(snapshot.Root)
+	if err != nil {
+		return err
+	}
+
+	// git reports paths from the resolved repository root, so compare resolved paths
+	root, err := filepath.Abs(snapshot.Root)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return err
+	}
+
+	kept := []SnapshotEntry{}
+	for _, entry := range snapshot.Files {
+		if changed[filepath.Join(root, filepath.FromSlash(entry.Path))] {
+			kept = append(kept, entry)
+		}
+	}
+	snapshot.Files = kept
+
+	return nil
+}
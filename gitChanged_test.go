@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseGitPorcelain(t *testing.T) {
+
+	out := []byte(" M modified.txt\x00" +
+		"A  added.txt\x00" +
+		"R  renamed new.txt\x00renamed old.txt\x00" +
+		"C  copy.txt\x00original.txt\x00" +
+		" D deleted.txt\x00" +
+		"D  staged delete.txt\x00" +
+		"?? untracked/file.txt\x00" +
+		"MM both.txt\x00")
+
+	got := parseGitPorcelain(out)
+	want := []string{"modified.txt", "added.txt", "renamed new.txt", "copy.txt", "untracked/file.txt", "both.txt"}
+	if !equalStrings(got, want) {
+		t.Errorf("parseGitPorcelain = %q, want %q", got, want)
+	}
+
+	if got := parseGitPorcelain(nil); len(got) != 0 {
+		t.Errorf("empty output gave %q", got)
+	}
+}
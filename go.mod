@@ -1,3 +1,5 @@
 module github.com/putteror/fileManager
 
 go 1.21.6
+
+require golang.org/x/text v0.14.0
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sync"
+)
+
+// Hashing memory is bounded: every hash streams the file through one pooled
+// 32 KiB buffer, and hashFiles runs at most maxConcurrent hashes at once, so
+// peak buffer memory is about maxConcurrent * 32 KiB however large the files are.
+const hashBufferSize = 32 * 1024
+
+var hashBuffers = sync.Pool{
+	New: func() interface{} {
+		buffer := make([]byte, hashBufferSize)
+		return &buffer
+	},
+}
+
+// Function to compute the hex sha256 of a file's contents
+func hashFile(path string) (string, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buffer := hashBuffers.Get().(*[]byte)
+	defer hashBuffers.Put(buffer)
+
+	// Hide *os.File's WriteTo so io.CopyBuffer really uses our buffer
+	hash := sha256.New()
+	if _, err := io.CopyBuffer(hash, struct{ io.Reader }{file}, *buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashOne is swapped out in tests to watch how many hashes run at once
+var hashOne = hashFile
+
+// Function to pick how many files may be hashed at once: MaxConcurrentHashes,
+// else the worker count, else one at a time
+func hashConcurrency(opts Options) int {
+
+	switch {
+	case opts.MaxConcurrentHashes > 0:
+		return opts.MaxConcurrentHashes
+	case opts.Workers > 0:
+		return opts.Workers
+	}
+
+	return 1
+}
+
+// Function to hash many files with at most maxConcurrent running at once,
+// returning path -> hash or the first error
+func hashFiles(paths []string, maxConcurrent int) (map[string]string, error) {
+
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+
+	var mu sync.Mutex
+	var firstErr error
+	hashes := make(map[string]string, len(paths))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrent)
+	for _, path := range paths {
+
+		// Acquire before starting the goroutine so idle ones don't pile up either
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			hash, err := hashOne(path)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			hashes[path] = hash
+		}(path)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return hashes, nil
+}
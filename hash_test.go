@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Function to write count files of size bytes each and return their paths
+func writeLargeFiles(tb testing.TB, dir string, count int, size int) []string {
+
+	data := make([]byte, size)
+	var paths []string
+	for i := 0; i < count; i++ {
+		data[0] = byte(i)
+		path := filepath.Join(dir, fmt.Sprintf("large_%d.bin", i))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			tb.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+func TestHashFilesBoundsConcurrency(t *testing.T) {
+
+	paths := writeLargeFiles(t, t.TempDir(), 12, 4<<20)
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	hashOne = func(path string) (string, error) {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+
+		// Give the other goroutines a chance to overlap
+		time.Sleep(5 * time.Millisecond)
+		defer func() {
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+
+		return hashFile(path)
+	}
+	defer func() { hashOne = hashFile }()
+
+	for _, limit := range []int{1, 3} {
+		peak = 0
+		hashes, err := hashFiles(paths, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hashes) != len(paths) {
+			t.Errorf("limit %d: got %d hashes, want %d", limit, len(hashes), len(paths))
+		}
+		if peak > limit {
+			t.Errorf("limit %d: %d hashes ran at once", limit, peak)
+		}
+	}
+}
+
+func TestHashConcurrency(t *testing.T) {
+
+	cases := []struct {
+		opts Options
+		want int
+	}{
+		{Options{}, 1},
+		{Options{Workers: 4}, 4},
+		{Options{Workers: 4, MaxConcurrentHashes: 2}, 2},
+	}
+	for _, c := range cases {
+		if got := hashConcurrency(c.opts); got != c.want {
+			t.Errorf("hashConcurrency(%+v) = %d, want %d", c.opts, got, c.want)
+		}
+	}
+}
+
+// Allocations per run stay near zero per byte: the pooled buffer is reused
+// rather than each file being read into memory
+func BenchmarkHashFilesLarge(b *testing.B) {
+
+	paths := writeLargeFiles(b, b.TempDir(), 8, 8<<20)
+	b.SetBytes(int64(len(paths)) * 8 << 20)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := hashFiles(paths, 2); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
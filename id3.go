@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var errNoID3 = errors.New("no ID3 tags")
+
+// ID3v2 frame IDs for the fields we read, by tag version
+var id3Frames = map[string]string{
+	"TIT2": "Title", "TPE1": "Artist", "TALB": "Album", "TRCK": "Track", "TYER": "Year", "TDRC": "Year",
+	"TT2": "Title", "TP1": "Artist", "TAL": "Album", "TRK": "Track", "TYE": "Year",
+}
+
+// Function to read Title, Artist, Album, Track and Year from ID3v2 and ID3v1 tags.
+// ID3v2 values win; ID3v1 fills in whatever is missing.
+func readID3Tags(path string) (map[string]string, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tags := make(map[string]string)
+	readID3v2(file, tags)
+
+	if info, err := file.Stat(); err == nil && info.Size() >= 128 {
+		v1 := make([]byte, 128)
+		if _, err := file.ReadAt(v1, info.Size()-128); err == nil {
+			readID3v1(v1, tags)
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil, errNoID3
+	}
+
+	return tags, nil
+}
+
+// Function to decode a 28-bit "syncsafe" integer
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// Function to read the text frames of an ID3v2.2, 2.3 or 2.4 tag at the start of a file
+func readID3v2(r io.Reader, tags map[string]string) {
+
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:3]) != "ID3" {
+		return
+	}
+
+	// The header's size can't be trusted on a corrupt or truncated file, so
+	// read through a limit rather than allocating it up front; frames cut off
+	// by the end of the file are dropped below
+	version := header[3]
+	body, err := io.ReadAll(io.LimitReader(r, int64(syncsafe(header[6:]))))
+	if err != nil {
+		return
+	}
+
+	// Skip the extended header, sized differently in 2.3 and 2.4
+	if header[5]&0x40 != 0 && version >= 3 && len(body) >= 4 {
+		size := int(binary.BigEndian.Uint32(body)) + 4
+		if version == 4 {
+			size = syncsafe(body)
+		}
+		if size > len(body) {
+			return
+		}
+		body = body[size:]
+	}
+
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	for len(body) >= headerLen && body[0] != 0 {
+
+		id := string(body[:idLen])
+		var size int
+		switch version {
+		case 2:
+			size = int(body[3])<<16 | int(body[4])<<8 | int(body[5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(body[4:]))
+		default:
+			size = syncsafe(body[4:])
+		}
+		if size < 0 || headerLen+size > len(body) {
+			return
+		}
+
+		if field, ok := id3Frames[id]; ok {
+			if value := decodeID3Text(body[headerLen : headerLen+size]); value != "" && tags[field] == "" {
+				tags[field] = value
+			}
+		}
+		body = body[headerLen+size:]
+	}
+}
+
+// Function to decode an ID3v2 text frame, whose first byte names its encoding
+func decodeID3Text(frame []byte) string {
+
+	if len(frame) < 2 {
+		return ""
+	}
+
+	encoding, data := frame[0], frame[1:]
+	var text string
+	switch encoding {
+	case 1, 2:
+		order := binary.ByteOrder(binary.BigEndian)
+		if encoding == 1 && len(data) >= 2 {
+			if data[0] == 0xFF && data[1] == 0xFE {
+				order = binary.LittleEndian
+			}
+			if (data[0] == 0xFF && data[1] == 0xFE) || (data[0] == 0xFE && data[1] == 0xFF) {
+				data = data[2:]
+			}
+		}
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = order.Uint16(data[i*2:])
+		}
+		text = string(utf16.Decode(units))
+	case 3:
+		text = string(data)
+	default:
+		text = latin1(data)
+	}
+
+	return strings.TrimSpace(strings.TrimRight(text, "\x00"))
+}
+
+// Function to decode ISO-8859-1 bytes
+func latin1(data []byte) string {
+
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+
+	return string(runes)
+}
+
+// Function to fill missing fields from a 128-byte ID3v1 tag
+func readID3v1(v1 []byte, tags map[string]string) {
+
+	if string(v1[:3]) != "TAG" {
+		return
+	}
+
+	field := func(b []byte) string {
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			b = b[:i]
+		}
+		return strings.TrimSpace(latin1(b))
+	}
+
+	values := map[string]string{
+		"Title":  field(v1[3:33]),
+		"Artist": field(v1[33:63]),
+		"Album":  field(v1[63:93]),
+		"Year":   field(v1[93:97]),
+	}
+
+	// ID3v1.1 keeps the track number in the last byte of the comment
+	if v1[125] == 0 && v1[126] != 0 {
+		values["Track"] = strconv.Itoa(int(v1[126]))
+	}
+
+	for key, value := range values {
+		if value != "" && tags[key] == "" {
+			tags[key] = value
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// Function to build an ID3v2 tag with text frames for the given version (2, 3 or 4)
+func buildID3v2(version byte, frames [][2]string) []byte {
+
+	var body bytes.Buffer
+	for _, frame := range frames {
+		data := append([]byte{3}, frame[1]...)
+		body.WriteString(frame[0])
+		switch version {
+		case 2:
+			body.Write([]byte{byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data))})
+		case 3:
+			binary.Write(&body, binary.BigEndian, uint32(len(data)))
+			body.Write([]byte{0, 0})
+		default:
+			body.Write(syncsafeBytes(len(data)))
+			body.Write([]byte{0, 0})
+		}
+		body.Write(data)
+	}
+
+	tag := append([]byte{'I', 'D', '3', version, 0, 0}, syncsafeBytes(body.Len())...)
+	return append(tag, body.Bytes()...)
+}
+
+// Function to encode a 28-bit syncsafe integer
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n>>21) & 0x7f, byte(n>>14) & 0x7f, byte(n>>7) & 0x7f, byte(n) & 0x7f}
+}
+
+// Function to build a 128-byte ID3v1.1 tag
+func buildID3v1(title string, artist string, track byte) []byte {
+
+	v1 := make([]byte, 128)
+	copy(v1, "TAG")
+	copy(v1[3:33], title)
+	copy(v1[33:63], artist)
+	v1[126] = track
+
+	return v1
+}
+
+func TestReadID3Tags(t *testing.T) {
+
+	dir := t.TempDir()
+	frames := [][2]string{{"TIT2", "Song"}, {"TPE1", "Band"}, {"TRCK", "7"}}
+	writeTestFiles(t, dir, map[string]string{
+		"v22.mp3": string(buildID3v2(2, [][2]string{{"TT2", "Old"}, {"TP1", "Timer"}})),
+		"v23.mp3": string(buildID3v2(3, frames)) + "audio",
+		"v24.mp3": string(buildID3v2(4, frames)) + "audio",
+		"v1.mp3":  "audio" + string(buildID3v1("Classic", "Artist", 3)),
+		"both.mp3": string(buildID3v2(3, [][2]string{{"TIT2", "From v2"}})) + "audio" +
+			string(buildID3v1("From v1", "Only in v1", 0)),
+		"none.mp3": "just audio",
+	})
+
+	want := map[string]map[string]string{
+		"v22.mp3":  {"Title": "Old", "Artist": "Timer"},
+		"v23.mp3":  {"Title": "Song", "Artist": "Band", "Track": "7"},
+		"v24.mp3":  {"Title": "Song", "Artist": "Band", "Track": "7"},
+		"v1.mp3":   {"Title": "Classic", "Artist": "Artist", "Track": "3"},
+		"both.mp3": {"Title": "From v2", "Artist": "Only in v1"},
+	}
+	for name, tags := range want {
+		got, err := readID3Tags(filepath.Join(dir, name))
+		if err != nil || !reflect.DeepEqual(got, tags) {
+			t.Errorf("%s: tags = %v, %v, want %v", name, got, err, tags)
+		}
+	}
+
+	if _, err := readID3Tags(filepath.Join(dir, "none.mp3")); err != errNoID3 {
+		t.Errorf("untagged file gave %v, want errNoID3", err)
+	}
+}
+
+func TestReadID3TagsTruncated(t *testing.T) {
+
+	// The header claims a 256 MiB tag but the file ends after one frame
+	tag := buildID3v2(3, [][2]string{{"TIT2", "Cut"}, {"TPE1", "Short"}})
+	copy(tag[6:10], []byte{0x7f, 0x7f, 0x7f, 0x7f})
+	truncated := tag[:len(tag)-4]
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"cut.mp3": string(truncated)})
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	tags, err := readID3Tags(filepath.Join(dir, "cut.mp3"))
+	runtime.ReadMemStats(&after)
+
+	if err != nil || tags["Title"] != "Cut" || tags["Artist"] != "" {
+		t.Errorf("tags = %v, %v", tags, err)
+	}
+	if grown := after.TotalAlloc - before.TotalAlloc; grown > 1<<20 {
+		t.Errorf("reading a truncated tag allocated %d bytes", grown)
+	}
+}
+
+func TestRenameByID3(t *testing.T) {
+
+	dir := t.TempDir()
+	tagged := func(title string, artist string) string {
+		return string(buildID3v2(3, [][2]string{{"TIT2", title}, {"TPE1", artist}})) + "audio"
+	}
+	writeTestFiles(t, dir, map[string]string{
+		"track01.mp3":      tagged("Song", "Band"),
+		"track02.mp3":      tagged("Song", "Band"),
+		"slash.MP3":        tagged("A/B: C?", "Band"),
+		"no title.mp3":     string(buildID3v2(3, [][2]string{{"TPE1", "Band"}})),
+		"Band - Other.mp3": tagged("Other", "Band"),
+		"readme.txt":       "not audio",
+	})
+
+	results, err := renameByID3(dir, `{{.Artist}} - {{.Title}}{{.Ext}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := make(map[string]string)
+	for _, result := range results {
+		statuses[filepath.Base(result.OldPath)] = result.Status + " " + filepath.Base(result.NewPath)
+	}
+	want := map[string]string{
+		"track01.mp3":      "renamed Band - Song.mp3",
+		"track02.mp3":      "renamed Band - Song_1.mp3",
+		"slash.MP3":        "renamed Band - A_B_ C_.MP3",
+		"no title.mp3":     "skipped .",
+		"Band - Other.mp3": "skipped Band - Other.mp3",
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("results = %v, want %v", statuses, want)
+	}
+
+	// A repeat run renames nothing and reports every file
+	again, err := renameByID3(dir, `{{.Artist}} - {{.Title}}{{.Ext}}`)
+	if err != nil || len(again) != 5 || countStatus(again, statusRenamed) != 0 {
+		t.Errorf("second run = %+v, %v", again, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "readme.txt")); err != nil {
+		t.Error("non-mp3 file was touched")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Function to record every file under folderPath with its size and content hash
+func captureState(folderPath string) (map[string]string, error) {
+
+	snapshot, err := scanSnapshot(folderPath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]string)
+	for _, entry := range snapshot.Files {
+		hash, err := hashFile(filepath.Join(folderPath, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			return nil, err
+		}
+		state[entry.Path] = fmt.Sprintf("%d:%s", entry.Size, hash)
+	}
+
+	return state, nil
+}
+
+// Function to run an operation twice and check the second run changed nothing,
+// so scheduled runs can be repeated safely
+func verifyIdempotent(folderPath string, op func() error) error {
+
+	if err := op(); err != nil {
+		return fmt.Errorf("first run: %v", err)
+	}
+	before, err := captureState(folderPath)
+	if err != nil {
+		return err
+	}
+
+	if err := op(); err != nil {
+		return fmt.Errorf("second run: %v", err)
+	}
+	after, err := captureState(folderPath)
+	if err != nil {
+		return err
+	}
+
+	var changes []string
+	for path, state := range before {
+		if after[path] == "" {
+			changes = append(changes, "removed "+path)
+		} else if after[path] != state {
+			changes = append(changes, "modified "+path)
+		}
+	}
+	for path := range after {
+		if before[path] == "" {
+			changes = append(changes, "added "+path)
+		}
+	}
+
+	if len(changes) > 0 {
+		sort.Strings(changes)
+		return fmt.Errorf("second run changed the folder: %s", strings.Join(changes, ", "))
+	}
+
+	return nil
+}
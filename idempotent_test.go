@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyIdempotentCoreFunctions(t *testing.T) {
+
+	files := map[string]string{
+		"My Photo.jpeg": "jpeg",
+		"b  notes.txt":  "notes",
+		"song.mp3":      "mp3",
+		"report.TXT":    "report",
+		"draft.md":      "draft",
+	}
+
+	ops := map[string]func(dir string) error{
+		"changeFileExtensions": func(dir string) error {
+			_, err := changeFileExtensionsWithOptions("md", "txt", dir, Options{})
+			return err
+		},
+		"organizeByExtension": func(dir string) error {
+			_, err := organizeByExtension(dir)
+			return err
+		},
+		"organizeByMediaCategory": func(dir string) error {
+			_, err := organizeByMediaCategory(dir)
+			return err
+		},
+		"organizeByDate": func(dir string) error {
+			_, err := organizeByDate(dir, DateFromModTime)
+			return err
+		},
+		"collapseSeparators": func(dir string) error {
+			_, err := collapseSeparators(dir, "_")
+			return err
+		},
+		"partitionBySize": func(dir string) error {
+			_, err := partitionBySize(dir, 5)
+			return err
+		},
+		"enforceMaxPerDir": func(dir string) error {
+			_, err := enforceMaxPerDir(dir, 2)
+			return err
+		},
+		"renameWithTemplate": func(dir string) error {
+			_, err := renameWithTemplate(dir, "file_{{.Index}}{{.Ext}}", OrderName)
+			return err
+		},
+		"convertNameCase": func(dir string) error {
+			_, err := convertNameCase(dir, CaseSnake)
+			return err
+		},
+	}
+
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, files)
+			if err := verifyIdempotent(dir, func() error { return op(dir) }); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestVerifyIdempotentReportsChanges(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a"})
+
+	runs := 0
+	err := verifyIdempotent(dir, func() error {
+		runs++
+		writeTestFiles(t, dir, map[string]string{"a.txt": strings.Repeat("a", runs)})
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "modified a.txt") {
+		t.Errorf("err = %v, want modified a.txt", err)
+	}
+}
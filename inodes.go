@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// freeInodes is swapped out to simulate a filesystem running out of inodes
+var freeInodes = statfsFreeInodes
+
+// Function to find the closest folder at or above path that already exists
+func existingAncestor(path string) string {
+
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// Function to refuse a run that would create more files than the target
+// filesystem has inodes for, which otherwise fails confusingly half-way
+func checkFreeInodes(path string, needed int) error {
+
+	if needed <= 0 {
+		return nil
+	}
+
+	free, known, err := freeInodes(existingAncestor(path))
+	if err != nil || !known {
+		return nil
+	}
+
+	if free < uint64(needed) {
+		return fmt.Errorf("%s has %d free inodes but %d files would be created", path, free, needed)
+	}
+
+	return nil
+}
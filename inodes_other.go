@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd)
+
+package main
+
+// Function to read the free inode count; not available on this platform
+func statfsFreeInodes(path string) (free uint64, known bool, err error) {
+	return 0, false, nil
+}
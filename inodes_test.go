@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Function to make freeInodes give a fixed answer for the rest of the test
+func fakeFreeInodes(t *testing.T, free uint64, known bool, err error) {
+
+	freeInodes = func(string) (uint64, bool, error) { return free, known, err }
+	t.Cleanup(func() { freeInodes = statfsFreeInodes })
+}
+
+func TestCheckFreeInodes(t *testing.T) {
+
+	dir := t.TempDir()
+
+	fakeFreeInodes(t, 5, true, nil)
+	if err := checkFreeInodes(dir, 5); err != nil {
+		t.Errorf("exactly enough inodes: %v", err)
+	}
+	if err := checkFreeInodes(filepath.Join(dir, "not", "made", "yet"), 6); err == nil || !strings.Contains(err.Error(), "5 free inodes but 6") {
+		t.Errorf("too few inodes: err = %v", err)
+	}
+
+	// Unknown counts and probe errors never block a run
+	fakeFreeInodes(t, 0, false, nil)
+	if err := checkFreeInodes(dir, 100); err != nil {
+		t.Errorf("unknown count: %v", err)
+	}
+	fakeFreeInodes(t, 0, true, errors.New("statfs failed"))
+	if err := checkFreeInodes(dir, 100); err != nil {
+		t.Errorf("probe error: %v", err)
+	}
+}
+
+func TestStagedRunRefusedWithoutInodes(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.md": "a", "b.md": "b"})
+
+	fakeFreeInodes(t, 1, true, nil)
+	if _, err := changeFileExtensionsWithOptions("md", "txt", dir, Options{Staged: true}); err == nil {
+		t.Fatal("staged run with one free inode for two files was allowed")
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.md", "b.md"}) {
+		t.Errorf("refused run changed files: %v", got)
+	}
+
+	fakeFreeInodes(t, 2, true, nil)
+	if _, err := changeFileExtensionsWithOptions("md", "txt", dir, Options{Staged: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.txt", "b.txt"}) {
+		t.Errorf("files = %v", got)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+
+package main
+
+import "syscall"
+
+// Function to read the free inode count of the filesystem holding path.
+// known is false for filesystems that don't report inodes (Files == 0).
+func statfsFreeInodes(path string) (free uint64, known bool, err error) {
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, false, err
+	}
+
+	if stat.Files == 0 {
+		return 0, false, nil
+	}
+
+	return uint64(stat.Ffree), true, nil
+}
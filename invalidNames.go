@@ -0,0 +1,7 @@
+package main
+
+// InvalidName is a file whose name the current platform can't represent
+type InvalidName struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// Function to report names Windows can't represent; only meaningful on Windows
+func findInvalidWindowsNames(root string) ([]InvalidName, error) {
+	return nil, nil
+}
@@ -0,0 +1,75 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// Device names Windows reserves regardless of extension
+var reservedWindowsNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// Function to explain why Windows can't represent a name, or return "" if it can
+func invalidWindowsNameReason(name string) string {
+
+	// Go decodes unpaired UTF-16 surrogates to U+FFFD
+	if strings.ContainsRune(name, '\uFFFD') {
+		return "contains invalid UTF-16"
+	}
+
+	for _, r := range name {
+		if r < 0x20 {
+			return "contains a control character"
+		}
+	}
+
+	if i := strings.IndexAny(name, `<>:"|?*`); i >= 0 {
+		return "contains reserved character " + name[i:i+1]
+	}
+
+	if strings.HasSuffix(name, " ") || strings.HasSuffix(name, ".") {
+		return "ends with a space or dot"
+	}
+
+	stem := strings.ToUpper(strings.SplitN(name, ".", 2)[0])
+	if reservedWindowsNames[strings.TrimRight(stem, " ")] {
+		return "uses reserved device name " + stem
+	}
+
+	if len(utf16.Encode([]rune(name))) > 255 {
+		return "is longer than 255 UTF-16 units"
+	}
+
+	return ""
+}
+
+// Function to walk a tree and report names Windows can't represent, without renaming anything
+func findInvalidWindowsNames(root string) ([]InvalidName, error) {
+
+	var invalid []InvalidName
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			invalid = append(invalid, InvalidName{Path: path, Reason: err.Error()})
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+
+		if reason := invalidWindowsNameReason(info.Name()); reason != "" {
+			invalid = append(invalid, InvalidName{Path: path, Reason: reason})
+		}
+		return nil
+	})
+
+	return invalid, err
+}
@@ -0,0 +1,49 @@
+//go:build windows
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInvalidWindowsNameReason(t *testing.T) {
+
+	invalid := map[string]string{
+		"CON":                    "reserved device name",
+		"nul.txt":                "reserved device name",
+		"com1 .log":              "reserved device name",
+		"what?.txt":              "reserved character",
+		"a<b":                    "reserved character",
+		"trailing.":              "space or dot",
+		"trailing ":              "space or dot",
+		"tab\there":              "control character",
+		"bad\uFFFDutf16":         "invalid UTF-16",
+		strings.Repeat("x", 256): "longer than 255",
+	}
+	for name, want := range invalid {
+		if got := invalidWindowsNameReason(name); !strings.Contains(got, want) {
+			t.Errorf("invalidWindowsNameReason(%q) = %q, want it to mention %q", name, got, want)
+		}
+	}
+
+	for _, name := range []string{"console.txt", "report.final.pdf", "COM10", strings.Repeat("x", 255)} {
+		if got := invalidWindowsNameReason(name); got != "" {
+			t.Errorf("invalidWindowsNameReason(%q) = %q, want valid", name, got)
+		}
+	}
+}
+
+func TestFindInvalidWindowsNamesOnCleanTree(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"ok.txt": "", "sub/also ok.md": ""})
+
+	invalid, err := findInvalidWindowsNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invalid) != 0 {
+		t.Errorf("clean tree reported %v", invalid)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build linux
+
+package main
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// Function to read the one-minute load average from /proc/loadavg
+func systemLoad() (float64, error) {
+
+	data, err := ioutil.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(strings.Fields(string(data) + " 0")[0], 64)
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// Function to read the system load; not available on this platform, so pacing
+// needs a LoadSampler here
+func systemLoad() (float64, error) {
+	return 0, errors.New("system load is not available on this platform")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Operations recorded in a Manifest
+const (
+	operationRename   = "rename"
+	operationMove     = "move"
+	operationOrganize = "organize"
+)
+
+// ManifestEntry records one file touched by a mutating operation
+type ManifestEntry struct {
+	Operation   string    `json:"operation"`
+	Source      string    `json:"source"`
+	Destination string    `json:"destination"`
+	Status      string    `json:"status"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Manifest collects the same kind of record from every mutating operation,
+// so one review or undo tool can read the output of any of them.
+// Pass one in Options.Manifest; a nil Manifest records nothing.
+type Manifest struct {
+	mu      sync.Mutex
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// Function to add one record to the manifest
+func (m *Manifest) record(operation string, source string, destination string, status string) {
+
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Entries = append(m.Entries, ManifestEntry{
+		Operation:   operation,
+		Source:      source,
+		Destination: destination,
+		Status:      status,
+		Timestamp:   time.Now(),
+	})
+}
+
+// Function to add a record for every result that was actually attempted
+func (m *Manifest) recordResults(operation string, results []RenameResult) {
+
+	for _, result := range results {
+		if result.Status == statusRenamed || result.Status == statusFailed {
+			m.record(operation, result.OldPath, result.NewPath, result.Status)
+		}
+	}
+}
+
+// Function to add a renamed record for every old -> new path pair that changed,
+// in source order, for operations that rename a whole mapping at once
+func (m *Manifest) recordPaths(operation string, paths map[string]string) {
+
+	sources := make([]string, 0, len(paths))
+	for oldPath, newPath := range paths {
+		if oldPath != newPath {
+			sources = append(sources, oldPath)
+		}
+	}
+	sort.Strings(sources)
+
+	for _, oldPath := range sources {
+		m.record(operation, oldPath, paths[oldPath], statusRenamed)
+	}
+}
+
+// Function to save the manifest as JSON
+func (m *Manifest) writeFile(path string) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Function to turn manifest entries into "operation src -> dst status" lines relative to root
+func manifestLines(t *testing.T, root string, manifest *Manifest) []string {
+
+	rel := func(path string) string {
+		r, err := filepath.Rel(root, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return filepath.ToSlash(r)
+	}
+
+	lines := []string{}
+	for _, entry := range manifest.Entries {
+		if entry.Timestamp.IsZero() {
+			t.Errorf("entry %+v has no timestamp", entry)
+		}
+		lines = append(lines, entry.Operation+" "+rel(entry.Source)+" -> "+rel(entry.Destination)+" "+entry.Status)
+	}
+
+	return lines
+}
+
+func TestManifestPerOperation(t *testing.T) {
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	pinArchiveNow(t, now)
+
+	cases := []struct {
+		name  string
+		files map[string]string
+		run   func(dir string, opts Options) error
+		want  []string
+	}{
+		{"changeFileExtensions", map[string]string{"a.md": ""}, func(dir string, opts Options) error {
+			_, err := changeFileExtensionsWithOptions("md", "txt", dir, opts)
+			return err
+		}, []string{"rename a.md -> a.txt renamed"}},
+		{"changeFileExtensions into TargetDir", map[string]string{"a.md": ""}, func(dir string, opts Options) error {
+			opts.TargetDir = filepath.Join(dir, "out")
+			_, err := changeFileExtensionsWithOptions("md", "txt", dir, opts)
+			return err
+		}, []string{"move a.md -> out/a.txt renamed"}},
+		{"archiveOldFiles", map[string]string{"old.log": ""}, func(dir string, opts Options) error {
+			backdate(t, dir, "old.log", now.Add(-48*time.Hour))
+			_, err := archiveOldFilesWithOptions(dir, 24*time.Hour, opts)
+			return err
+		}, []string{"move old.log -> archive/old.log renamed"}},
+		{"organizeByExtension", map[string]string{"a.png": ""}, func(dir string, opts Options) error {
+			_, err := organizeByExtensionWithOptions(dir, opts)
+			return err
+		}, []string{"organize a.png -> png/a.png renamed"}},
+		{"organizeByMediaCategory", map[string]string{"a.mp3": ""}, func(dir string, opts Options) error {
+			_, err := organizeByMediaCategoryWithOptions(dir, opts)
+			return err
+		}, []string{"organize a.mp3 -> Audio/a.mp3 renamed"}},
+		{"organizeByDate", map[string]string{"a.txt": ""}, func(dir string, opts Options) error {
+			backdate(t, dir, "a.txt", time.Date(2022, 3, 4, 12, 0, 0, 0, time.Local))
+			_, err := organizeByDateWithOptions(dir, DateFromModTime, opts)
+			return err
+		}, []string{"organize a.txt -> 2022/03/04/a.txt renamed"}},
+		{"partitionBySize", map[string]string{"big": "0123456789"}, func(dir string, opts Options) error {
+			_, err := partitionBySizeWithOptions(dir, 5, opts)
+			return err
+		}, []string{"organize big -> large/big renamed"}},
+		{"enforceMaxPerDir", map[string]string{"a": "", "b": ""}, func(dir string, opts Options) error {
+			_, err := enforceMaxPerDirWithOptions(dir, 1, opts)
+			return err
+		}, []string{"organize b -> part_2/b renamed"}},
+		{"restoreLatestBackup", map[string]string{"a.txt.20240101": ""}, func(dir string, opts Options) error {
+			_, err := restoreLatestBackupWithOptions(dir, opts)
+			return err
+		}, []string{"rename a.txt.20240101 -> a.txt renamed"}},
+		{"alignNamesByContent", map[string]string{"IMG_1.jpg": "beach", "ref/holiday.jpg": "beach"}, func(dir string, opts Options) error {
+			_, err := alignNamesByContentWithOptions(dir, filepath.Join(dir, "ref"), opts)
+			return err
+		}, []string{"rename IMG_1.jpg -> holiday.jpg renamed"}},
+		{"renameWithTemplate", map[string]string{"a.txt": "", "b.txt": ""}, func(dir string, opts Options) error {
+			_, err := renameWithTemplateWithOptions(dir, "{{.Index}}{{.Ext}}", OrderName, opts)
+			return err
+		}, []string{"rename a.txt -> 1.txt renamed", "rename b.txt -> 2.txt renamed"}},
+		{"chronoRenumber", map[string]string{"shot.jpg": string(buildEXIFJPEG(binary.BigEndian, "2020:01:02 03:04:05"))}, func(dir string, opts Options) error {
+			_, _, err := chronoRenumberWithOptions(dir, "photo_{{.Index}}{{.Ext}}", opts)
+			return err
+		}, []string{"rename shot.jpg -> photo_1.jpg renamed"}},
+		{"renameWithSequence", map[string]string{"a.txt": ""}, func(dir string, opts Options) error {
+			_, err := renameWithSequenceWithOptions(dir, filepath.Join(t.TempDir(), "counter"), "seq_{{.Index}}{{.Ext}}", opts)
+			return err
+		}, []string{"rename a.txt -> seq_1.txt renamed"}},
+		{"stripCommonPrefix", map[string]string{"trip_a.jpg": "", "trip_b.jpg": ""}, func(dir string, opts Options) error {
+			_, err := stripCommonPrefixWithOptions(dir, opts)
+			return err
+		}, []string{"rename trip_a.jpg -> a.jpg renamed", "rename trip_b.jpg -> b.jpg renamed"}},
+		{"collapseSeparators", map[string]string{"a  b.txt": ""}, func(dir string, opts Options) error {
+			_, err := collapseSeparatorsWithOptions(dir, "_", opts)
+			return err
+		}, []string{"rename a  b.txt -> a_b.txt renamed"}},
+		{"convertNameCase", map[string]string{"MyFile.txt": ""}, func(dir string, opts Options) error {
+			_, err := convertNameCaseWithOptions(dir, CaseSnake, opts)
+			return err
+		}, []string{"rename MyFile.txt -> my_file.txt renamed"}},
+		{"renameFromCSV", map[string]string{"a.txt": "", "map.csv": "a.txt,b.txt\nmissing.txt,c.txt\n"}, func(dir string, opts Options) error {
+			_, err := renameFromCSVWithOptions(dir, filepath.Join(dir, "map.csv"), opts)
+			return err
+		}, []string{"rename a.txt -> b.txt renamed"}},
+		{"renameByID3", map[string]string{"track.mp3": string(buildID3v2(3, [][2]string{{"TPE1", "Artist"}, {"TIT2", "Song"}}))}, func(dir string, opts Options) error {
+			_, err := renameByID3WithOptions(dir, "{{.Artist}} - {{.Title}}{{.Ext}}", opts)
+			return err
+		}, []string{"rename track.mp3 -> Artist - Song.mp3 renamed"}},
+		{"fixExtensionsByContent", map[string]string{"image.dat": "\x89PNG\r\n\x1a\nrest"}, func(dir string, opts Options) error {
+			_, err := fixExtensionsByContentWithOptions(dir, 0.5, opts)
+			return err
+		}, []string{"rename image.dat -> image.png renamed"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, c.files)
+
+			manifest := &Manifest{}
+			if err := c.run(dir, Options{Manifest: manifest}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := manifestLines(t, dir, manifest); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("manifest = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestManifestRecordsFailuresAndWritesJSON(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "", "b.txt": "", "map.csv": "a.txt,b.txt\n"})
+
+	manifest := &Manifest{}
+	if _, err := renameFromCSVWithOptions(dir, filepath.Join(dir, "map.csv"), Options{Manifest: manifest}); err != nil {
+		t.Fatal(err)
+	}
+	if got := manifestLines(t, dir, manifest); !equalStrings(got, []string{"rename a.txt -> b.txt failed"}) {
+		t.Errorf("manifest = %q", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := manifest.writeFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Entries []map[string]interface{} `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Entries) != 1 {
+		t.Fatalf("decoded %s", data)
+	}
+	for _, key := range []string{"operation", "source", "destination", "status", "timestamp"} {
+		if _, ok := decoded.Entries[0][key]; !ok {
+			t.Errorf("entry is missing %q: %s", key, data)
+		}
+	}
+
+	// A nil manifest is safe to record into
+	var none *Manifest
+	none.record(operationRename, "a", "b", statusRenamed)
+}
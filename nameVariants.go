@@ -0,0 +1,65 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Common markers added to copies of a file: "name (1)", "name copy", "name - Copy (2)", "name-final"
+var defaultDuplicateMarkers = []*regexp.Regexp{
+	regexp.MustCompile(` ?\(\d+\)$`),
+	regexp.MustCompile(`(?i)( - |[ _-])copy( \(\d+\)| \d+)?$`),
+	regexp.MustCompile(`(?i)[ _-]final$`),
+}
+
+// Function to reduce a file name to its canonical form by stripping duplicate markers
+func canonicalVariantName(name string, markers []*regexp.Regexp) string {
+
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+
+	// Markers stack up ("report-final (2)"), so keep stripping until nothing changes
+	for changed := true; changed; {
+		changed = false
+		for _, marker := range markers {
+			if stripped := marker.ReplaceAllString(stem, ""); stripped != stem && stripped != "" {
+				stem = stripped
+				changed = true
+			}
+		}
+	}
+
+	return strings.ToLower(stem + ext)
+}
+
+// Function to group files that look like variants of one another, keyed by canonical name
+func findNameVariants(folderPath string) (map[string][]string, error) {
+	return findNameVariantsWithMarkers(folderPath, defaultDuplicateMarkers)
+}
+
+// Function to group files whose names match once the given markers are stripped
+func findNameVariantsWithMarkers(folderPath string, markers []*regexp.Regexp) (map[string][]string, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]string)
+	for _, file := range files {
+		canonical := canonicalVariantName(file.Name(), markers)
+		groups[canonical] = append(groups[canonical], file.Name())
+	}
+
+	variants := make(map[string][]string)
+	for canonical, names := range groups {
+		if len(names) > 1 {
+			sort.Strings(names)
+			variants[canonical] = names
+		}
+	}
+
+	return variants, nil
+}
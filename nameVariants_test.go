@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestCanonicalVariantName(t *testing.T) {
+
+	cases := map[string]string{
+		"Report.pdf":            "report.pdf",
+		"Report (1).pdf":        "report.pdf",
+		"Report(2).pdf":         "report.pdf",
+		"Report copy.pdf":       "report.pdf",
+		"Report copy 3.pdf":     "report.pdf",
+		"Report - Copy.pdf":     "report.pdf",
+		"Report - Copy (2).pdf": "report.pdf",
+		"Report_copy.pdf":       "report.pdf",
+		"Report-final.pdf":      "report.pdf",
+		"Report_FINAL (2).pdf":  "report.pdf",
+		"Report-final copy.pdf": "report.pdf",
+		"copy.pdf":              "copy.pdf",
+		"(1).pdf":               "(1).pdf",
+		"Photocopy.pdf":         "photocopy.pdf",
+		"Report 2019.pdf":       "report 2019.pdf",
+	}
+	for name, want := range cases {
+		if got := canonicalVariantName(name, defaultDuplicateMarkers); got != want {
+			t.Errorf("canonicalVariantName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFindNameVariants(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"Budget.xlsx": "", "Budget (1).xlsx": "", "budget - Copy.xlsx": "",
+		"notes.txt": "", "notes-final.txt": "",
+		"alone.doc":    "",
+		"v1_draft.doc": "", "v2_draft.doc": "",
+	})
+
+	variants, err := findNameVariants(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"budget.xlsx": {"Budget (1).xlsx", "Budget.xlsx", "budget - Copy.xlsx"},
+		"notes.txt":   {"notes-final.txt", "notes.txt"},
+	}
+	if !reflect.DeepEqual(variants, want) {
+		t.Errorf("variants = %v, want %v", variants, want)
+	}
+
+	// Custom markers replace the defaults
+	variants, err = findNameVariantsWithMarkers(dir, []*regexp.Regexp{regexp.MustCompile(`^v\d+_`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string][]string{"draft.doc": {"v1_draft.doc", "v2_draft.doc"}}; !reflect.DeepEqual(variants, want) {
+		t.Errorf("custom variants = %v, want %v", variants, want)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Function to find names in the same folder that are equal after NFC
+// normalization but differ byte-wise, such as a composed "é" and "e" plus a
+// combining accent. Such files collide when moved to a normalizing
+// filesystem like APFS or HFS+. Each group holds the colliding paths.
+func findNormalizationCollisions(root string) ([][]string, error) {
+
+	groups := make(map[string][]string)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+
+		key := filepath.Join(filepath.Dir(path), norm.NFC.String(info.Name()))
+		groups[key] = append(groups[key], path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	collisions := [][]string{}
+	for _, paths := range groups {
+		if len(paths) > 1 {
+			sort.Strings(paths)
+			collisions = append(collisions, paths)
+		}
+	}
+
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisions[i][0] < collisions[j][0]
+	})
+
+	return collisions, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindNormalizationCollisions(t *testing.T) {
+
+	composed, decomposed := "caf\u00e9.txt", "cafe\u0301.txt"
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		composed:              "composed",
+		"sub/" + composed:     "composed",
+		"sub/" + decomposed:   "decomposed",
+		"other/" + decomposed: "alone",
+		"plain.txt":           "ascii",
+	})
+
+	// Normalizing filesystems store both spellings as one file
+	entries, err := os.ReadDir(filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Skip("filesystem normalizes names, so the pair can't be created")
+	}
+
+	collisions, err := findNormalizationCollisions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{filepath.Join(dir, "sub", decomposed), filepath.Join(dir, "sub", composed)}}
+	if !reflect.DeepEqual(collisions, want) {
+		t.Errorf("collisions = %q, want %q", collisions, want)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import "time"
+
+// CompressMode selects what happens to files after they are renamed
+type CompressMode int
+
+const (
+	CompressNone CompressMode = iota
+	CompressGzip
+)
+
+// Options tunes how a rename run behaves; the zero value renames in place
+type Options struct {
+	// Compress gzips each renamed file and removes the uncompressed copy
+	Compress CompressMode
+
+	// Limit stops after this many successful renames; 0 means no limit.
+	// Files past the limit are reported as skipped so the remainder is visible.
+	Limit int
+
+	// Recursive descends into subfolders instead of only the top level
+	Recursive bool
+
+	// DryRun plans the run and returns the plan without changing anything
+	DryRun bool
+
+	// BackupDir, when set, receives a copy of every file before it is renamed.
+	// Copies keep their path relative to the folder being processed, so
+	// same-named files from different subfolders don't collide.
+	BackupDir string
+
+	// VerifyCopy checks the sha256 of both sides before deleting the source
+	// when a cross-filesystem rename falls back to copy and delete
+	VerifyCopy bool
+
+	// GitChanged only processes files that git reports as changed or untracked
+	GitChanged bool
+
+	// TargetDir moves renamed files into this folder instead of leaving them
+	// in place. Combined with Recursive this flattens the tree, so the run is
+	// refused up front if two sources would land on the same destination.
+	TargetDir string
+
+	// ConfineToRoot skips, with a reported reason, any symlink whose resolved
+	// target lies outside the folder being processed
+	ConfineToRoot bool
+
+	// IgnoreCase makes name matching case-insensitive
+	IgnoreCase bool
+
+	// Staged copies every file to a staging folder and verifies it before
+	// committing anything, so a failure part-way leaves the folder unchanged.
+	// Compress and BackupDir are not applied in staged runs.
+	Staged bool
+
+	// Workers renames with this many goroutines when above 1. Results still
+	// come back in plan order. Ignored when Limit is set.
+	Workers int
+
+	// Progress, when set, is called after each planned file is processed with
+	// the number done so far and the total. Calls never overlap.
+	Progress func(done int, total int)
+
+	// CanonicalizeExt renames alias extensions to their canonical form
+	// (.jpeg to .jpg) when organizing by extension
+	CanonicalizeExt bool
+
+	// MaxConcurrentHashes caps how many files are hashed at once; 0 falls
+	// back to Workers. Each running hash holds one 32 KiB buffer.
+	MaxConcurrentHashes int
+
+	// KeepBackup restores backups by copying them, leaving the backup in place
+	KeepBackup bool
+
+	// LoadThreshold, when above 0, pauses before each file while the system
+	// load is higher than this. The load comes from LoadSampler, or from
+	// /proc/loadavg on Linux; elsewhere pacing is skipped without a sampler.
+	LoadThreshold float64
+	LoadSampler   func() (float64, error)
+
+	// PaceDelay is how long each pause lasts; defaults to one second
+	PaceDelay time.Duration
+
+	// Expect, when set, aborts the run before anything is renamed (dry runs
+	// included) if the number of matched files falls outside the range
+	Expect *CountRange
+
+	// Manifest, when set, receives a record of every file the run touched
+	Manifest *Manifest
+
+	// UseTrash sends files that would be deleted to the OS trash instead
+	UseTrash bool
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"sort"
+)
+
+// Order selects how files are sorted before numbering
+type Order int
+
+const (
+	OrderName Order = iota
+	OrderModTime
+	OrderSize
+)
+
+// Function to sort files by the given order, falling back to name on ties
+func sortFiles(files []os.FileInfo, order Order) {
+
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+
+		switch order {
+		case OrderModTime:
+			if !a.ModTime().Equal(b.ModTime()) {
+				return a.ModTime().Before(b.ModTime())
+			}
+		case OrderSize:
+			if a.Size() != b.Size() {
+				return a.Size() < b.Size()
+			}
+		}
+
+		return a.Name() < b.Name()
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// DateSource selects which date organizeByDate files by
+type DateSource int
+
+const (
+	DateFromModTime DateSource = iota
+	// DateFromEXIF uses EXIF DateTimeOriginal, falling back to the modification time
+	DateFromEXIF
+)
+
+// Function to move files into YYYY/MM/DD folders by modification or EXIF date
+func organizeByDate(folderPath string, source DateSource) (map[string]string, error) {
+	return organizeByDateWithOptions(folderPath, source, Options{})
+}
+
+// Function to return the slash separated YYYY/MM/DD folder for a date
+func dateFolder(date time.Time) string {
+	return date.Format("2006/01/02")
+}
+
+// Function to organize by date, recording moves in opts.Manifest
+func organizeByDateWithOptions(folderPath string, source DateSource, opts Options) (map[string]string, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string)
+	for _, file := range files {
+
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		src := filepath.Join(folderPath, file.Name())
+		date := file.ModTime()
+		if source == DateFromEXIF {
+			if taken, err := readEXIFDateTime(src); err == nil {
+				date = taken
+			}
+		}
+
+		dateDir := filepath.FromSlash(dateFolder(date))
+		dst, err := moveFile(src, filepath.Join(folderPath, dateDir))
+		if err != nil {
+			opts.Manifest.record(operationOrganize, src, filepath.Join(folderPath, dateDir, file.Name()), statusFailed)
+			fmt.Printf("Failed to move %s to %s: %v\n", src, dateDir, err)
+			continue
+		}
+
+		opts.Manifest.record(operationOrganize, src, dst, statusRenamed)
+
+		rel, _ := filepath.Rel(folderPath, dst)
+		mapping[file.Name()] = filepath.ToSlash(rel)
+	}
+
+	return mapping, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestOrganizeByDate(t *testing.T) {
+
+	for _, source := range []DateSource{DateFromModTime, DateFromEXIF} {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{
+			"shot.jpg":  string(buildEXIFJPEG(binary.LittleEndian, "2019:12:31 23:59:59")),
+			"notes.txt": "no exif",
+		})
+		backdate(t, dir, "shot.jpg", time.Date(2023, 5, 6, 12, 0, 0, 0, time.Local))
+		backdate(t, dir, "notes.txt", time.Date(2022, 1, 9, 12, 0, 0, 0, time.Local))
+
+		mapping, err := organizeByDate(dir, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]string{"shot.jpg": "2023/05/06/shot.jpg", "notes.txt": "2022/01/09/notes.txt"}
+		if source == DateFromEXIF {
+			want["shot.jpg"] = "2019/12/31/shot.jpg"
+		}
+		for name, path := range want {
+			if mapping[name] != path {
+				t.Errorf("source %d: %s -> %s, want %s", source, name, mapping[name], path)
+			}
+			readTestFile(t, dir, path)
+		}
+	}
+}
+
+func TestOrganizeByDateCollision(t *testing.T) {
+
+	dir := t.TempDir()
+	stamp := time.Date(2021, 2, 3, 0, 0, 0, 0, time.Local)
+	writeTestFiles(t, dir, map[string]string{"a.txt": "new", "2021/02/03/a.txt": "already filed"})
+	backdate(t, dir, "a.txt", stamp)
+
+	mapping, err := organizeByDate(dir, DateFromModTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapping["a.txt"] != "2021/02/03/a_1.txt" || readTestFile(t, dir, "2021/02/03/a.txt") != "already filed" {
+		t.Errorf("mapping = %v", mapping)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Media category folder names
+const (
+	categoryImages    = "Images"
+	categoryVideos    = "Videos"
+	categoryAudio     = "Audio"
+	categoryDocuments = "Documents"
+	categoryOther     = "Other"
+)
+
+// Content types that count as documents besides text/*
+var documentTypes = map[string]bool{
+	"application/pdf":                         true,
+	"application/msword":                      true,
+	"application/rtf":                         true,
+	"application/vnd.ms-excel":                true,
+	"application/vnd.ms-powerpoint":           true,
+	"application/vnd.oasis.opendocument.text": true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+}
+
+// Categories by extension for content sniffing can't pin down. This is kept
+// here rather than asking mime.TypeByExtension, whose answers depend on the
+// mime tables installed on the machine.
+var extensionCategories = map[string]string{
+	".jpg": categoryImages, ".jpeg": categoryImages, ".png": categoryImages, ".gif": categoryImages,
+	".bmp": categoryImages, ".webp": categoryImages, ".tif": categoryImages, ".tiff": categoryImages,
+	".heic": categoryImages, ".svg": categoryImages, ".ico": categoryImages,
+	".mp4": categoryVideos, ".m4v": categoryVideos, ".mov": categoryVideos, ".mkv": categoryVideos,
+	".avi": categoryVideos, ".webm": categoryVideos, ".wmv": categoryVideos, ".mpg": categoryVideos,
+	".mpeg": categoryVideos,
+	".mp3":  categoryAudio, ".m4a": categoryAudio, ".aac": categoryAudio, ".flac": categoryAudio,
+	".wav": categoryAudio, ".ogg": categoryAudio, ".opus": categoryAudio, ".wma": categoryAudio,
+	".txt": categoryDocuments, ".md": categoryDocuments, ".csv": categoryDocuments, ".rtf": categoryDocuments,
+	".pdf": categoryDocuments, ".doc": categoryDocuments, ".docx": categoryDocuments, ".xls": categoryDocuments,
+	".xlsx": categoryDocuments, ".ppt": categoryDocuments, ".pptx": categoryDocuments, ".odt": categoryDocuments,
+	".ods": categoryDocuments, ".odp": categoryDocuments,
+}
+
+// Function to map a content type to a media category
+func mediaCategory(contentType string) string {
+
+	contentType = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return categoryImages
+	case strings.HasPrefix(contentType, "video/"):
+		return categoryVideos
+	case strings.HasPrefix(contentType, "audio/"), contentType == "application/ogg":
+		return categoryAudio
+	case strings.HasPrefix(contentType, "text/"), documentTypes[contentType]:
+		return categoryDocuments
+	}
+
+	return categoryOther
+}
+
+// Function to pick the media category of a single file
+func fileMediaCategory(path string) string {
+
+	contentType, _, _, err := detectContentType(path)
+	if err != nil {
+		return categoryOther
+	}
+
+	return refineMediaCategory(contentType, path)
+}
+
+// Function to pick the media category of a single file in an fs.FS
+func fileMediaCategoryFS(fsys fs.FS, name string) string {
+
+	contentType, _, _, err := detectContentTypeFS(fsys, name)
+	if err != nil {
+		return categoryOther
+	}
+
+	return refineMediaCategory(contentType, name)
+}
+
+// Function to pick the media category for a file's sniffed content type and name
+func refineMediaCategory(contentType string, path string) string {
+
+	category := mediaCategory(contentType)
+
+	// Sniffing can't tell office files from zips or plain text from csv,
+	// so let the extension refine those generic answers
+	if category == categoryOther || contentType == "application/zip" || strings.HasPrefix(contentType, "text/plain") {
+		if byExt, ok := extensionCategories[strings.ToLower(filepath.Ext(path))]; ok {
+			category = byExt
+		}
+	}
+
+	return category
+}
+
+// Function to move files into Images, Videos, Audio, Documents and Other folders
+func organizeByMediaCategory(folderPath string) (map[string]string, error) {
+	return organizeByMediaCategoryWithOptions(folderPath, Options{})
+}
+
+// Function to organize by media category, recording moves in opts.Manifest
+func organizeByMediaCategoryWithOptions(folderPath string, opts Options) (map[string]string, error) {
+
+	files, err := ioutil.ReadDir(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string)
+	for _, file := range files {
+
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		src := filepath.Join(folderPath, file.Name())
+		category := fileMediaCategory(src)
+
+		dst, err := moveFile(src, filepath.Join(folderPath, category))
+		if err != nil {
+			opts.Manifest.record(operationOrganize, src, filepath.Join(folderPath, category, file.Name()), statusFailed)
+			fmt.Printf("Failed to move %s to %s: %v\n", src, category, err)
+			continue
+		}
+
+		opts.Manifest.record(operationOrganize, src, dst, statusRenamed)
+
+		fmt.Printf("Moved: %s -> %s\n", src, dst)
+		mapping[file.Name()] = category
+	}
+
+	return mapping, nil
+}
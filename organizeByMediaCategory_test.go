@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMediaCategory(t *testing.T) {
+
+	cases := map[string]string{
+		"image/png":                 categoryImages,
+		"video/webm":                categoryVideos,
+		"audio/mpeg":                categoryAudio,
+		"application/ogg":           categoryAudio,
+		"text/plain; charset=utf-8": categoryDocuments,
+		"application/pdf":           categoryDocuments,
+		"application/octet-stream":  categoryOther,
+		"":                          categoryOther,
+	}
+
+	for contentType, want := range cases {
+		if got := mediaCategory(contentType); got != want {
+			t.Errorf("mediaCategory(%q) = %s, want %s", contentType, got, want)
+		}
+	}
+}
+
+func TestOrganizeByMediaCategory(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"photo.dat":   "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"clip.bin":    "\x1a\x45\xdf\xa3\x01\x00\x00\x00",
+		"song.mp3":    "ID3\x03\x00\x00\x00\x00\x00\x00",
+		"paper.pdf":   "%PDF-1.4\n",
+		"notes.txt":   "just some notes\n",
+		"report.docx": "PK\x03\x04\x14\x00\x06\x00",
+		"table.csv":   "a,b\n1,2\n",
+		"blob":        "\x00\x01\x02\x03\xfe\xff",
+	})
+
+	mapping, err := organizeByMediaCategory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"photo.dat":   categoryImages,
+		"clip.bin":    categoryVideos,
+		"song.mp3":    categoryAudio,
+		"paper.pdf":   categoryDocuments,
+		"notes.txt":   categoryDocuments,
+		"report.docx": categoryDocuments,
+		"table.csv":   categoryDocuments,
+		"blob":        categoryOther,
+	}
+	for name, category := range want {
+		if mapping[name] != category {
+			t.Errorf("%s went to %q, want %q", name, mapping[name], category)
+		}
+		readTestFile(t, dir, category+"/"+name)
+	}
+	if len(mapping) != len(want) {
+		t.Errorf("moved %d files, want %d", len(mapping), len(want))
+	}
+}
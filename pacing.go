@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Default pause between load checks when Options.PaceDelay is unset
+const defaultPaceDelay = time.Second
+
+// Longest a single file waits for the load to drop before going ahead anyway
+const maxPaceChecks = 10
+
+// pacer holds back operations while the system load is above a threshold.
+// Pacing is best effort: if the load can't be read, nothing is delayed.
+type pacer struct {
+	threshold float64
+	delay     time.Duration
+	sample    func() (float64, error)
+	sleep     func(time.Duration)
+
+	mu       sync.Mutex
+	disabled bool
+}
+
+// Function to build the pacer for a run, or nil when pacing is off
+func newPacer(opts Options) *pacer {
+
+	if opts.LoadThreshold <= 0 {
+		return nil
+	}
+
+	p := &pacer{threshold: opts.LoadThreshold, delay: opts.PaceDelay, sample: opts.LoadSampler, sleep: time.Sleep}
+	if p.delay <= 0 {
+		p.delay = defaultPaceDelay
+	}
+	if p.sample == nil {
+		p.sample = systemLoad
+	}
+
+	return p
+}
+
+// Function to wait while the load is above the threshold, up to maxPaceChecks pauses
+func (p *pacer) wait() {
+
+	if p == nil {
+		return
+	}
+
+	for i := 0; i < maxPaceChecks; i++ {
+
+		p.mu.Lock()
+		if p.disabled {
+			p.mu.Unlock()
+			return
+		}
+		load, err := p.sample()
+		if err != nil {
+			p.disabled = true
+		}
+		p.mu.Unlock()
+
+		if err != nil || load <= p.threshold {
+			return
+		}
+		p.sleep(p.delay)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Function to build a sampler that returns loads in turn, repeating the last one
+func scriptedLoad(loads ...float64) (func() (float64, error), *int) {
+
+	calls := 0
+	return func() (float64, error) {
+		load := loads[len(loads)-1]
+		if calls < len(loads) {
+			load = loads[calls]
+		}
+		calls++
+		return load, nil
+	}, &calls
+}
+
+func TestPacerWaitsForLoadToDrop(t *testing.T) {
+
+	sample, calls := scriptedLoad(4, 3, 0.5)
+	p := newPacer(Options{LoadThreshold: 1, PaceDelay: time.Millisecond, LoadSampler: sample})
+
+	var slept []time.Duration
+	p.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	p.wait()
+	if len(slept) != 2 || *calls != 3 {
+		t.Errorf("slept %v after %d samples, want 2 pauses and 3 samples", slept, *calls)
+	}
+	for _, d := range slept {
+		if d != time.Millisecond {
+			t.Errorf("paused for %v, want PaceDelay", d)
+		}
+	}
+
+	// Once the load is low there's no pause at all
+	slept = nil
+	p.wait()
+	if len(slept) != 0 {
+		t.Errorf("paused %v at low load", slept)
+	}
+}
+
+func TestPacerGivesUpAfterMaxChecks(t *testing.T) {
+
+	sample, _ := scriptedLoad(9)
+	p := newPacer(Options{LoadThreshold: 1, LoadSampler: sample})
+
+	pauses := 0
+	p.sleep = func(d time.Duration) {
+		if d != defaultPaceDelay {
+			t.Errorf("paused for %v, want the default delay", d)
+		}
+		pauses++
+	}
+
+	p.wait()
+	if pauses != maxPaceChecks {
+		t.Errorf("paused %d times, want %d", pauses, maxPaceChecks)
+	}
+}
+
+func TestPacerDisabledBySamplerError(t *testing.T) {
+
+	calls := 0
+	p := newPacer(Options{LoadThreshold: 1, LoadSampler: func() (float64, error) {
+		calls++
+		return 0, errors.New("no load average here")
+	}})
+	p.sleep = func(time.Duration) { t.Error("paused although the load is unknown") }
+
+	p.wait()
+	p.wait()
+	if calls != 1 {
+		t.Errorf("sampler called %d times, want once before pacing switches off", calls)
+	}
+
+	if newPacer(Options{}) != nil {
+		t.Error("pacer built with no threshold")
+	}
+}
+
+func TestApplyPlanSamplesLoadPerFile(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.md": "", "b.md": "", "c.md": ""})
+
+	sample, calls := scriptedLoad(0.1)
+	results, err := changeFileExtensionsWithOptions("md", "txt", dir, Options{LoadThreshold: 2, LoadSampler: sample})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countStatus(results, statusRenamed) != 3 || *calls != 3 {
+		t.Errorf("renamed %d with %d load samples, want 3 and 3", countStatus(results, statusRenamed), *calls)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Folder names used by partitionBySize
+const (
+	largeFolderName = "large"
+	smallFolderName = "small"
+)
+
+// Function to move files bigger than threshold bytes into large/ and the rest into small/.
+// Returns the names moved into each folder.
+func partitionBySize(folderPath string, threshold int64) (map[string][]string, error) {
+	return partitionBySizeWithOptions(folderPath, threshold, Options{})
+}
+
+// Function to partition by size, recording moves in opts.Manifest. A file of
+// exactly threshold bytes counts as small.
+func partitionBySizeWithOptions(folderPath string, threshold int64, opts Options) (map[string][]string, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := map[string][]string{largeFolderName: {}, smallFolderName: {}}
+	for _, file := range files {
+
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		folder := smallFolderName
+		if file.Size() > threshold {
+			folder = largeFolderName
+		}
+
+		src := filepath.Join(folderPath, file.Name())
+		dst, err := moveFile(src, filepath.Join(folderPath, folder))
+		if err != nil {
+			opts.Manifest.record(operationOrganize, src, filepath.Join(folderPath, folder, file.Name()), statusFailed)
+			fmt.Printf("Failed to move %s to %s: %v\n", src, folder, err)
+			continue
+		}
+
+		opts.Manifest.record(operationOrganize, src, dst, statusRenamed)
+
+		mapping[folder] = append(mapping[folder], filepath.Base(dst))
+	}
+
+	return mapping, nil
+}
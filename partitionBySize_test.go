@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestPartitionBySizeBoundary(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"under.bin": strings.Repeat("x", 99),
+		"equal.bin": strings.Repeat("x", 100),
+		"over.bin":  strings.Repeat("x", 101),
+		"empty.bin": "",
+	})
+
+	manifest := &Manifest{}
+	mapping, err := partitionBySizeWithOptions(dir, 100, Options{Manifest: manifest})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(mapping[smallFolderName])
+	want := map[string][]string{
+		largeFolderName: {"over.bin"},
+		smallFolderName: {"empty.bin", "equal.bin", "under.bin"},
+	}
+	if !reflect.DeepEqual(mapping, want) {
+		t.Errorf("mapping = %v, want %v", mapping, want)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"large/over.bin", "small/empty.bin", "small/equal.bin", "small/under.bin"}) {
+		t.Errorf("files = %v", got)
+	}
+	if len(manifest.Entries) != 4 || manifest.Entries[0].Operation != operationOrganize {
+		t.Errorf("manifest = %+v", manifest.Entries)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Result statuses
+const (
+	statusPlanned = "planned"
+	statusRenamed = "renamed"
+	statusSkipped = "skipped"
+	statusFailed  = "failed"
+)
+
+// RenameResult describes what happened, or would happen, to one file
+type RenameResult struct {
+	OldPath string `json:"oldPath"`
+	NewPath string `json:"newPath"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Size    int64  `json:"size,omitempty"`
+}
+
+// Function to make sure an extension starts with a dot
+func normalizeExt(ext string) string {
+
+	if !strings.Contains(ext, ".") {
+		ext = "." + ext
+	}
+
+	return ext
+}
+
+// Function to plan an extension change against a snapshot without touching the disk
+func planExtensionChange(oldExt string, newExt string, snapshot *Snapshot) []RenameResult {
+
+	oldExt = normalizeExt(oldExt)
+	newExt = normalizeExt(newExt)
+
+	plan := []RenameResult{}
+	for _, entry := range snapshot.Files {
+
+		if !strings.HasSuffix(entry.Path, oldExt) {
+			continue
+		}
+
+		oldPath := filepath.Join(snapshot.Root, filepath.FromSlash(entry.Path))
+		plan = append(plan, RenameResult{
+			OldPath: oldPath,
+			NewPath: strings.TrimSuffix(oldPath, oldExt) + newExt,
+			Status:  statusPlanned,
+		})
+	}
+
+	return plan
+}
+
+// Function to carry out a plan for files under root, recording the outcome of every entry
+func applyPlan(plan []RenameResult, root string, opts Options) []RenameResult {
+
+	// Limit means "the first N in order", which only sequential runs can promise
+	if opts.Workers > 1 && opts.Limit == 0 {
+		return applyPlanParallel(plan, root, opts)
+	}
+
+	progress := newProgressCounter(plan, opts)
+	pace := newPacer(opts)
+	renamed := 0
+	results := make([]RenameResult, 0, len(plan))
+	for _, result := range plan {
+
+		if result.Status != statusPlanned {
+			results = append(results, result)
+			continue
+		}
+
+		if opts.Limit > 0 && renamed >= opts.Limit {
+			skipForLimit(&result, opts.Limit)
+			results = append(results, result)
+			progress.step()
+			continue
+		}
+
+		pace.wait()
+		result = applyOne(result, root, opts)
+		if result.Status == statusRenamed {
+			renamed++
+		}
+		results = append(results, result)
+		progress.step()
+	}
+
+	return results
+}
+
+// Function to carry out a plan with several workers. Each worker writes into
+// the slot of the entry it was handed, so results come back in plan order
+// no matter which rename finishes first.
+func applyPlanParallel(plan []RenameResult, root string, opts Options) []RenameResult {
+
+	progress := newProgressCounter(plan, opts)
+	pace := newPacer(opts)
+	results := make([]RenameResult, len(plan))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				pace.wait()
+				results[i] = applyOne(plan[i], root, opts)
+				progress.step()
+			}
+		}()
+	}
+
+	for i := range plan {
+		if plan[i].Status != statusPlanned {
+			results[i] = plan[i]
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// progressCounter reports progress to Options.Progress from any number of workers
+type progressCounter struct {
+	mu       sync.Mutex
+	done     int
+	total    int
+	callback func(done int, total int)
+}
+
+// Function to set up progress reporting for the planned entries of a plan
+func newProgressCounter(plan []RenameResult, opts Options) *progressCounter {
+	return &progressCounter{total: countStatus(plan, statusPlanned), callback: opts.Progress}
+}
+
+// Function to record one more processed entry
+func (p *progressCounter) step() {
+
+	if p.callback == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.callback(p.done, p.total)
+}
+
+// Function to back up, rename and optionally compress a single planned entry
+func applyOne(result RenameResult, root string, opts Options) RenameResult {
+
+	if result.Status != statusPlanned {
+		return result
+	}
+
+	if opts.BackupDir != "" {
+		if err := backupFile(result.OldPath, root, opts.BackupDir); err != nil {
+			result.Status = statusFailed
+			result.Reason = "backup: " + err.Error()
+			return result
+		}
+	}
+
+	if err := renameOrCopy(result.OldPath, result.NewPath, opts); err != nil {
+		result.Status = statusFailed
+		result.Reason = err.Error()
+		return result
+	}
+	result.Status = statusRenamed
+
+	if opts.Compress == CompressGzip {
+		// The rename already happened, so a gzip failure doesn't undo "renamed"
+		gzPath, size, err := gzipFile(result.NewPath, opts)
+		if err != nil {
+			result.Reason = "renamed but not compressed: gzip: " + err.Error()
+		} else {
+			result.NewPath = gzPath
+			result.Size = size
+		}
+	}
+
+	return result
+}
+
+// Function to copy a file into backupDir at the same path it has relative to
+// root. Backups from earlier runs are kept; a repeat backup of the same path
+// gets a _1, _2, ... suffix like any other collision.
+func backupFile(path string, root string, backupDir string) error {
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return err
+	}
+
+	dst := filepath.Join(backupDir, rel)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	return copyFile(path, uniquePath(dst))
+}
+
+// Function to leave an entry alone because the run's limit was reached
+func skipForLimit(result *RenameResult, limit int) {
+	result.Status = statusSkipped
+	result.Reason = fmt.Sprintf("limit of %d reached", limit)
+}
+
+// Function to count results with the given status
+func countStatus(results []RenameResult, status string) int {
+
+	count := 0
+	for _, result := range results {
+		if result.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPlanExtensionChange(t *testing.T) {
+
+	stamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	snapshot := &Snapshot{Root: "data", Files: []SnapshotEntry{
+		{Path: "a.txt", Size: 1, ModTime: stamp},
+		{Path: "b.md", Size: 2, ModTime: stamp},
+		{Path: "sub/c.txt", Size: 3, ModTime: stamp},
+		{Path: "notatxt", Size: 4, ModTime: stamp},
+	}}
+
+	plan := planExtensionChange("txt", ".md", snapshot)
+
+	want := []RenameResult{
+		{OldPath: filepath.Join("data", "a.txt"), NewPath: filepath.Join("data", "a.md"), Status: statusPlanned},
+		{OldPath: filepath.Join("data", "sub", "c.txt"), NewPath: filepath.Join("data", "sub", "c.md"), Status: statusPlanned},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("plan = %+v, want %+v", plan, want)
+	}
+}
+
+func TestApplyPlanLimit(t *testing.T) {
+
+	for _, staged := range []bool{false, true} {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{"e.txt": "", "a.txt": "", "c.txt": "", "b.txt": "", "d.txt": ""})
+
+		results, err := changeFileExtensionsWithOptions("txt", "md", dir, Options{Limit: 2, Staged: staged})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := countStatus(results, statusRenamed); got != 2 {
+			t.Errorf("staged=%v: renamed %d files, want exactly 2", staged, got)
+		}
+		if got := countStatus(results, statusSkipped); got != 3 {
+			t.Errorf("staged=%v: %d files left for later, want 3", staged, got)
+		}
+		if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.md", "b.md", "c.txt", "d.txt", "e.txt"}) {
+			t.Errorf("staged=%v: files = %v", staged, got)
+		}
+	}
+}
+
+func TestBackupMirrorsTree(t *testing.T) {
+
+	dir := t.TempDir()
+	backupDir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"notes.txt": "top", "a/notes.txt": "in a", "b/notes.txt": "in b"})
+
+	opts := Options{Recursive: true, BackupDir: backupDir}
+	results, err := changeFileExtensionsWithOptions("txt", "md", dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countStatus(results, statusRenamed) != 3 {
+		t.Fatalf("results = %+v", results)
+	}
+
+	for name, content := range map[string]string{"notes.txt": "top", "a/notes.txt": "in a", "b/notes.txt": "in b"} {
+		if got := readTestFile(t, backupDir, name); got != content {
+			t.Errorf("backup %s holds %q, want %q", name, got, content)
+		}
+	}
+
+	// A second run backing up the same paths keeps the first backups
+	writeTestFiles(t, dir, map[string]string{"notes.txt": "second"})
+	results, err = changeFileExtensionsWithOptions("txt", "text", dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countStatus(results, statusRenamed) != 1 {
+		t.Fatalf("second run = %+v", results)
+	}
+	if readTestFile(t, backupDir, "notes.txt") != "top" || readTestFile(t, backupDir, "notes_1.txt") != "second" {
+		t.Errorf("backups = %v", listTestFiles(t, backupDir))
+	}
+}
+
+func TestApplyPlanParallelKeepsPlanOrder(t *testing.T) {
+
+	dir := t.TempDir()
+	files := make(map[string]string)
+	for i := 0; i < 60; i++ {
+		files[fmt.Sprintf("file_%02d.txt", i)] = strings.Repeat("x", i*100)
+	}
+	writeTestFiles(t, dir, files)
+
+	snapshot, err := captureSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := planExtensionChange("txt", "md", snapshot)
+	plan[10].Status = statusSkipped
+	plan[10].Reason = "left alone"
+
+	var calls int32
+	results := applyPlan(plan, dir, Options{Workers: 8, Progress: func(done int, total int) { atomic.AddInt32(&calls, 1) }})
+
+	if len(results) != len(plan) {
+		t.Fatalf("got %d results for %d entries", len(results), len(plan))
+	}
+	for i := range plan {
+		if results[i].OldPath != plan[i].OldPath {
+			t.Fatalf("result %d is %s, want %s", i, results[i].OldPath, plan[i].OldPath)
+		}
+	}
+	if results[10].Status != statusSkipped || countStatus(results, statusRenamed) != 59 {
+		t.Errorf("statuses wrong: %d renamed", countStatus(results, statusRenamed))
+	}
+	if calls != 59 {
+		t.Errorf("progress called %d times, want 59", calls)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FSCapabilities describes what the filesystem holding a folder supports
+type FSCapabilities struct {
+	CaseSensitive bool `json:"caseSensitive"`
+	Symlinks      bool `json:"symlinks"`
+	Permissions   bool `json:"permissions"`
+	MaxNameLength int  `json:"maxNameLength"`
+}
+
+// Longest name probed for; anything beyond this is reported as this value
+const maxProbedNameLength = 1024
+
+// Function to find out what the filesystem under path supports by creating
+// and removing probe files in it
+func probeFilesystem(path string) (*FSCapabilities, error) {
+
+	probePath, err := createProbe(path)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(probePath)
+
+	caps := &FSCapabilities{}
+	if caps.CaseSensitive, err = probeIsCaseSensitive(probePath); err != nil {
+		return nil, err
+	}
+
+	link := probePath + ".link"
+	if err := os.Symlink(probePath, link); err == nil {
+		caps.Symlinks = true
+		os.Remove(link)
+	}
+
+	if err := os.Chmod(probePath, 0400); err == nil {
+		if info, err := os.Stat(probePath); err == nil && info.Mode().Perm() == 0400 {
+			caps.Permissions = true
+		}
+		os.Chmod(probePath, 0600)
+	}
+
+	caps.MaxNameLength = probeMaxNameLength(path)
+
+	return caps, nil
+}
+
+// Function to create an empty hidden probe file in path
+func createProbe(path string) (string, error) {
+
+	probe, err := os.CreateTemp(path, ".fmprobe-")
+	if err != nil {
+		return "", err
+	}
+	probe.Close()
+
+	return probe.Name(), nil
+}
+
+// Function to tell whether an existing probe file can be found under its upper-case name
+func probeIsCaseSensitive(probePath string) (bool, error) {
+
+	upper := filepath.Join(filepath.Dir(probePath), strings.ToUpper(filepath.Base(probePath)))
+	if _, err := os.Lstat(upper); errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// Function to find out only whether the filesystem under path tells names
+// apart by case, with a single probe file rather than a full probeFilesystem
+func probeCaseSensitive(path string) (bool, error) {
+
+	probePath, err := createProbe(path)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(probePath)
+
+	return probeIsCaseSensitive(probePath)
+}
+
+// Function to binary search for the longest file name the folder accepts
+func probeMaxNameLength(path string) int {
+
+	fits := func(length int) bool {
+		name := filepath.Join(path, ".fm"+strings.Repeat("n", length-3))
+		file, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			return false
+		}
+		file.Close()
+		os.Remove(name)
+		return true
+	}
+
+	low, high := 3, maxProbedNameLength
+	if !fits(low) {
+		return 0
+	}
+	for low < high {
+		mid := (low + high + 1) / 2
+		if fits(mid) {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+
+	return low
+}
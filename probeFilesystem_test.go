@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestProbeFilesystemTempDir(t *testing.T) {
+
+	dir := t.TempDir()
+	caps, err := probeFilesystem(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every common filesystem allows at least 255-byte names; the probe caps at its own limit
+	if caps.MaxNameLength < 255 || caps.MaxNameLength > maxProbedNameLength {
+		t.Errorf("MaxNameLength = %d", caps.MaxNameLength)
+	}
+
+	if runtime.GOOS == "linux" {
+		if !caps.CaseSensitive || !caps.Symlinks || !caps.Permissions {
+			t.Errorf("caps = %+v, want a case-sensitive filesystem with symlinks and permissions", caps)
+		}
+	}
+
+	sensitive, err := probeCaseSensitive(dir)
+	if err != nil || sensitive != caps.CaseSensitive {
+		t.Errorf("probeCaseSensitive = %v, %v, want %v", sensitive, err, caps.CaseSensitive)
+	}
+
+	// Probes clean up after themselves
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("probe left %d entries behind", len(entries))
+	}
+
+	if _, err := probeFilesystem(filepath.Join(dir, "missing")); err == nil {
+		t.Error("probing a missing folder succeeded")
+	}
+}
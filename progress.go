@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Function to estimate the time left from the rate so far; false while there is nothing to go on
+func estimateETA(done int, total int, elapsed time.Duration) (time.Duration, bool) {
+
+	if done <= 0 || elapsed <= 0 {
+		return 0, false
+	}
+	if done >= total {
+		return 0, true
+	}
+
+	perItem := elapsed / time.Duration(done)
+	return perItem * time.Duration(total-done), true
+}
+
+// Function to tell whether a file is an interactive terminal
+func isTerminal(file *os.File) bool {
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressRenderer draws Options.Progress updates for the CLI: a spinner
+// redrawn in place on a terminal, or a plain line every few seconds otherwise.
+// Its clock starts at the first update, so time spent at prompts or in an
+// editor before the run never counts towards the ETA.
+type progressRenderer struct {
+	w         io.Writer
+	tty       bool
+	started   bool
+	start     time.Time
+	startDone int
+	lastLine  time.Time
+	frame     int
+	now       func() time.Time
+}
+
+// Interval between plain progress lines when output isn't a terminal
+const plainProgressInterval = 2 * time.Second
+
+var spinnerFrames = []string{"|", "/", "-", `\`}
+
+// Function to create a renderer writing to file, detecting whether it is a terminal
+func newProgressRenderer(file *os.File) *progressRenderer {
+	return &progressRenderer{w: file, tty: isTerminal(file), now: time.Now}
+}
+
+// Function to format a progress line like "12/100 ETA 3s", where the rate
+// comes from the items done since startDone over elapsed
+func progressLine(done int, total int, startDone int, elapsed time.Duration) string {
+
+	line := fmt.Sprintf("%d/%d", done, total)
+	if eta, ok := estimateETA(done-startDone, total-startDone, elapsed); ok {
+		line += " ETA " + eta.Round(time.Second).String()
+	}
+
+	return line
+}
+
+// Function to draw one progress update; usable directly as Options.Progress
+func (p *progressRenderer) update(done int, total int) {
+
+	now := p.now()
+	if !p.started {
+		p.started, p.start, p.startDone = true, now, done
+	}
+	line := progressLine(done, total, p.startDone, now.Sub(p.start))
+
+	if p.tty {
+		p.frame = (p.frame + 1) % len(spinnerFrames)
+		fmt.Fprintf(p.w, "\r%s %s\033[K", spinnerFrames[p.frame], line)
+		if done >= total {
+			fmt.Fprintln(p.w)
+		}
+		return
+	}
+
+	if done >= total || now.Sub(p.lastLine) >= plainProgressInterval {
+		fmt.Fprintln(p.w, line)
+		p.lastLine = now
+	}
+}
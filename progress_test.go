@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateETA(t *testing.T) {
+
+	cases := []struct {
+		done, total int
+		elapsed     time.Duration
+		want        time.Duration
+		ok          bool
+	}{
+		{0, 10, time.Second, 0, false},
+		{5, 10, 0, 0, false},
+		{1, 10, time.Second, 9 * time.Second, true},
+		{5, 10, 10 * time.Second, 10 * time.Second, true},
+		{3, 4, 300 * time.Millisecond, 100 * time.Millisecond, true},
+		{10, 10, time.Minute, 0, true},
+	}
+	for _, c := range cases {
+		got, ok := estimateETA(c.done, c.total, c.elapsed)
+		if got != c.want || ok != c.ok {
+			t.Errorf("estimateETA(%d, %d, %v) = %v, %v, want %v, %v", c.done, c.total, c.elapsed, got, ok, c.want, c.ok)
+		}
+	}
+
+	if got := progressLine(2, 4, 0, 2*time.Second); got != "2/4 ETA 2s" {
+		t.Errorf("progressLine = %q", got)
+	}
+	if got := progressLine(3, 5, 1, 2*time.Second); got != "3/5 ETA 2s" {
+		t.Errorf("progressLine from a later start = %q", got)
+	}
+}
+
+func TestProgressRendererPlainOutput(t *testing.T) {
+
+	var out strings.Builder
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	renderer := &progressRenderer{w: &out, now: func() time.Time { return clock }}
+
+	// Without a terminal, lines come out at most every plainProgressInterval, plus the last one
+	for done := 1; done <= 4; done++ {
+		clock = clock.Add(time.Second)
+		renderer.update(done, 4)
+	}
+
+	// The first update only starts the clock, so it has no ETA yet
+	if want := "1/4\n3/4 ETA 1s\n4/4 ETA 0s\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestProgressRendererIgnoresTimeBeforeFirstUpdate(t *testing.T) {
+
+	var out strings.Builder
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	renderer := newProgressRenderer(os.Stderr)
+	renderer.w, renderer.tty = &out, false
+	renderer.now = func() time.Time { return clock }
+
+	// Ten minutes at the prompts, then one file every three seconds
+	clock = clock.Add(10 * time.Minute)
+	renderer.update(1, 4)
+	clock = clock.Add(3 * time.Second)
+	renderer.update(2, 4)
+
+	if want := "1/4\n2/4 ETA 6s\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Characters that aren't safe in file names on common filesystems
+var unsafeNameChars = strings.NewReplacer(
+	"<", "_", ">", "_", ":", "_", `"`, "_", "/", "_", `\`, "_", "|", "_", "?", "_", "*", "_",
+)
+
+// Function to make a tag value safe to use inside a file name
+func sanitizeNamePart(value string) string {
+
+	value = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, unsafeNameChars.Replace(value))
+
+	return strings.Trim(value, " .")
+}
+
+// Function to rename .mp3 files from their ID3 tags with a template such as
+// {{.Artist}} - {{.Title}}{{.Ext}}. Available fields are Artist, Title, Album,
+// Track and Year plus Stem and Ext; files missing a field the template uses are skipped.
+func renameByID3(folderPath string, tmpl string) ([]RenameResult, error) {
+	return renameByID3WithOptions(folderPath, tmpl, Options{})
+}
+
+// Function to rename from ID3 tags, recording renames in opts.Manifest
+func renameByID3WithOptions(folderPath string, tmpl string, opts Options) ([]RenameResult, error) {
+
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[string]bool)
+	results := []RenameResult{}
+	for _, file := range files {
+
+		ext := filepath.Ext(file.Name())
+		if !strings.EqualFold(ext, ".mp3") {
+			continue
+		}
+
+		result := RenameResult{OldPath: filepath.Join(folderPath, file.Name())}
+
+		tags, err := readID3Tags(result.OldPath)
+		if err != nil {
+			result.Status = statusSkipped
+			result.Reason = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		data := map[string]string{"Stem": strings.TrimSuffix(file.Name(), ext), "Ext": ext}
+		for key, value := range tags {
+			if value = sanitizeNamePart(value); value != "" {
+				data[key] = value
+			}
+		}
+
+		newName, err := executeNameTemplate(t, data)
+		if err != nil {
+			result.Status = statusSkipped
+			result.Reason = "missing tag: " + err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		result.NewPath = uniqueRenameTarget(result.OldPath, filepath.Join(folderPath, newName), claimed)
+		claimed[result.NewPath] = true
+		if result.NewPath == result.OldPath {
+			result.Status = statusSkipped
+			result.Reason = "already named from its tags"
+			results = append(results, result)
+			continue
+		}
+
+		if err := os.Rename(result.OldPath, result.NewPath); err != nil {
+			result.Status = statusFailed
+			result.Reason = err.Error()
+		} else {
+			result.Status = statusRenamed
+		}
+		results = append(results, result)
+	}
+
+	opts.Manifest.recordResults(operationRename, results)
+
+	return results, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Function to tell whether a CSV cell is a usable plain file name
+func validCSVName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// Function to rename files listed as old,new rows in a CSV file.
+// Malformed rows, missing sources and existing targets are reported and skipped;
+// an error reading the CSV itself stops the run.
+func renameFromCSV(folderPath string, csvPath string) ([]RenameResult, error) {
+	return renameFromCSVWithOptions(folderPath, csvPath, Options{})
+}
+
+// Function to rename from a CSV file, recording renames in opts.Manifest
+func renameFromCSVWithOptions(folderPath string, csvPath string, opts Options) ([]RenameResult, error) {
+
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := os.Stat(folderPath); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	results := []RenameResult{}
+	for row := 1; ; row++ {
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			results = append(results, RenameResult{Status: statusFailed, Reason: parseErr.Error()})
+			continue
+		}
+		if err != nil {
+			opts.Manifest.recordResults(operationRename, results)
+			return results, err
+		}
+
+		if row == 1 && len(record) == 2 && strings.EqualFold(record[0], "old") && strings.EqualFold(record[1], "new") {
+			continue
+		}
+
+		if len(record) != 2 || !validCSVName(record[0]) || !validCSVName(record[1]) {
+			results = append(results, RenameResult{
+				Status: statusFailed,
+				Reason: fmt.Sprintf("row %d: expected old,new file names, got %q", row, record),
+			})
+			continue
+		}
+
+		result := RenameResult{
+			OldPath: filepath.Join(folderPath, record[0]),
+			NewPath: filepath.Join(folderPath, record[1]),
+		}
+
+		switch _, err := os.Lstat(result.OldPath); {
+		case os.IsNotExist(err):
+			result.Status = statusSkipped
+			result.Reason = fmt.Sprintf("row %d: source file missing", row)
+		case err != nil:
+			result.Status = statusFailed
+			result.Reason = err.Error()
+		}
+		if result.Status != "" {
+			results = append(results, result)
+			continue
+		}
+
+		if _, err := os.Lstat(result.NewPath); err == nil {
+			result.Status = statusFailed
+			result.Reason = fmt.Sprintf("row %d: target already exists", row)
+		} else if err := os.Rename(result.OldPath, result.NewPath); err != nil {
+			result.Status = statusFailed
+			result.Reason = err.Error()
+		} else {
+			result.Status = statusRenamed
+		}
+		results = append(results, result)
+	}
+
+	opts.Manifest.recordResults(operationRename, results)
+
+	return results, nil
+}
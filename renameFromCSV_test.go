@@ -0,0 +1,59 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenameFromCSV(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"IMG_001.jpg": "1", "IMG_002.jpg": "2", "IMG_003.jpg": "3", "taken.jpg": "t"})
+
+	csvPath := filepath.Join(t.TempDir(), "map.csv")
+	writeTestFiles(t, filepath.Dir(csvPath), map[string]string{"map.csv": strings.Join([]string{
+		"old,new",
+		"IMG_001.jpg, beach.jpg",
+		"IMG_002.jpg,sub/escape.jpg",
+		"IMG_003.jpg,taken.jpg",
+		"missing.jpg,whatever.jpg",
+		"just_one_column",
+		`bad"quote,x.jpg`,
+		"IMG_003.jpg,city.jpg",
+	}, "\n") + "\n"})
+
+	results, err := renameFromCSV(dir, csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var statuses []string
+	for _, result := range results {
+		statuses = append(statuses, result.Status)
+	}
+	want := []string{statusRenamed, statusFailed, statusFailed, statusSkipped, statusFailed, statusFailed, statusRenamed}
+	if !equalStrings(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+		for _, result := range results {
+			t.Log(result)
+		}
+	}
+
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"IMG_002.jpg", "beach.jpg", "city.jpg", "taken.jpg"}) {
+		t.Errorf("files = %v", got)
+	}
+	if readTestFile(t, dir, "taken.jpg") != "t" {
+		t.Error("existing target was overwritten")
+	}
+}
+
+func TestRenameFromCSVReadError(t *testing.T) {
+
+	// Reading a folder fails the same way on every call; it must not loop forever
+	dir := t.TempDir()
+	results, err := renameFromCSV(dir, t.TempDir())
+	if err == nil {
+		t.Fatalf("reading a folder as CSV succeeded with %+v", results)
+	}
+}
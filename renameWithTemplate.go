@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateData holds the placeholders available to rename templates
+type templateData struct {
+	Index int
+	Total int
+	Stem  string
+	Ext   string
+}
+
+// Function to build a new base name from a template, rejecting names that can't be used
+func executeNameTemplate(t *template.Template, data interface{}) (string, error) {
+
+	var name strings.Builder
+	if err := t.Execute(&name, data); err != nil {
+		return "", err
+	}
+
+	newName := name.String()
+	if newName == "" || newName == "." || newName == ".." || strings.ContainsAny(newName, `/\`) {
+		return "", fmt.Errorf("template produced invalid name %q", newName)
+	}
+
+	return newName, nil
+}
+
+// Function to check that planned targets neither repeat nor clobber files outside the plan
+func checkTargets(mapping map[string]string) error {
+
+	targets := make(map[string]string)
+	for oldPath, newPath := range mapping {
+
+		if other, ok := targets[newPath]; ok {
+			return fmt.Errorf("%s and %s would both be renamed to %s", other, oldPath, newPath)
+		}
+		targets[newPath] = oldPath
+
+		if _, renamed := mapping[newPath]; renamed {
+			continue
+		}
+		if _, err := os.Lstat(newPath); err == nil {
+			return fmt.Errorf("cannot rename %s to %s: file exists", oldPath, newPath)
+		}
+	}
+
+	return nil
+}
+
+// Function to rename files from a template like {{.Stem}}_{{printf "%02d" .Index}}_of_{{.Total}}{{.Ext}}
+func renameWithTemplate(folderPath string, tmpl string, order Order) (map[string]string, error) {
+	return renameWithTemplateWithOptions(folderPath, tmpl, order, Options{})
+}
+
+// Function to rename from a template, recording renames in opts.Manifest
+func renameWithTemplateWithOptions(folderPath string, tmpl string, order Order, opts Options) (map[string]string, error) {
+
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+	sortFiles(files, order)
+
+	paths, mapping, err := planTemplateRenames(folderPath, t, files)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTargets(paths); err != nil {
+		return nil, err
+	}
+	if err := renameInTwoPhases(paths); err != nil {
+		return nil, err
+	}
+	opts.Manifest.recordPaths(operationRename, paths)
+
+	return mapping, nil
+}
+
+// Function to list the non-folder entries of a folder
+func listFiles(folderPath string) ([]os.FileInfo, error) {
+
+	entries, err := ioutil.ReadDir(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []os.FileInfo
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, entry)
+		}
+	}
+
+	return files, nil
+}
+
+// Function to name already-ordered files from a template, returning full-path
+// and base-name mappings
+func planTemplateRenames(folderPath string, t *template.Template, files []os.FileInfo) (map[string]string, map[string]string, error) {
+
+	paths := make(map[string]string)
+	mapping := make(map[string]string)
+	for i, file := range files {
+
+		ext := filepath.Ext(file.Name())
+		data := templateData{
+			Index: i + 1,
+			Total: len(files),
+			Stem:  strings.TrimSuffix(file.Name(), ext),
+			Ext:   ext,
+		}
+
+		newName, err := executeNameTemplate(t, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", file.Name(), err)
+		}
+
+		paths[filepath.Join(folderPath, file.Name())] = filepath.Join(folderPath, newName)
+		mapping[file.Name()] = newName
+	}
+
+	return paths, mapping, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestRenameWithTemplate(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"beach.jpg": "1", "city.jpg": "2", "alps.png": "3"})
+
+	mapping, err := renameWithTemplate(dir, `{{.Stem}}_{{printf "%02d" .Index}}_of_{{.Total}}{{.Ext}}`, OrderName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"alps.png": "alps_01_of_3.png", "beach.jpg": "beach_02_of_3.jpg", "city.jpg": "city_03_of_3.jpg"}
+	for old, name := range want {
+		if mapping[old] != name {
+			t.Errorf("%s -> %s, want %s", old, mapping[old], name)
+		}
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"alps_01_of_3.png", "beach_02_of_3.jpg", "city_03_of_3.jpg"}) {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestRenameWithTemplateSwapsNames(t *testing.T) {
+
+	// Ordered by size, file_2 comes first, so the two names trade places
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"file_1.txt": "longer", "file_2.txt": "s"})
+
+	if _, err := renameWithTemplate(dir, `file_{{.Index}}{{.Ext}}`, OrderSize); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readTestFile(t, dir, "file_1.txt"); got != "s" {
+		t.Errorf("file_1.txt holds %q, want %q", got, "s")
+	}
+	if got := readTestFile(t, dir, "file_2.txt"); got != "longer" {
+		t.Errorf("file_2.txt holds %q, want %q", got, "longer")
+	}
+}
+
+func TestRenameWithTemplateCollisions(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b"})
+
+	_, err := renameWithTemplate(dir, `same{{.Ext}}`, OrderName)
+	if err == nil || !strings.Contains(err.Error(), "both be renamed") {
+		t.Errorf("err = %v, want a duplicate-target error", err)
+	}
+
+	// A folder in the way of a target is outside the plan and must not be clobbered
+	if err := os.Mkdir(filepath.Join(dir, "a_1.txt"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err = renameWithTemplate(dir, `{{.Stem}}_1{{.Ext}}`, OrderName)
+	if err == nil || !strings.Contains(err.Error(), "file exists") {
+		t.Errorf("err = %v, want a file-exists error", err)
+	}
+
+	_, err = renameWithTemplate(dir, `{{.Missing}}`, OrderName)
+	if err == nil {
+		t.Error("unknown placeholder was accepted")
+	}
+
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.txt", "b.txt"}) {
+		t.Errorf("failed runs touched the folder: %v", got)
+	}
+}
+
+func TestRenameInTwoPhasesRollsBackChain(t *testing.T) {
+
+	// b can't become d, a non-empty folder, after a has already taken b's name
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a": "A", "b": "B", "d/x": "X"})
+	join := func(name string) string { return filepath.Join(dir, name) }
+
+	err := renameInTwoPhases(map[string]string{join("a"): join("b"), join("b"): join("d")})
+	if err == nil {
+		t.Fatal("renaming onto a non-empty folder succeeded")
+	}
+
+	for name, want := range map[string]string{"a": "A", "b": "B", "d/x": "X"} {
+		if got := readTestFile(t, dir, name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a", "b", "d/x"}) {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestRenameInTwoPhasesRollsBackSwap(t *testing.T) {
+
+	dir := t.TempDir()
+	files := map[string]string{"a": "A", "b": "B", "c": "C"}
+	join := func(name string) string { return filepath.Join(dir, name) }
+	rotation := map[string]string{join("a"): join("b"), join("b"): join("c"), join("c"): join("a")}
+
+	// Fail each of the six steps in turn; every failure must leave the files as they were
+	for failAt := 1; failAt <= 6; failAt++ {
+		writeTestFiles(t, dir, files)
+
+		step := 0
+		phaseRename = func(oldPath string, newPath string) error {
+			step++
+			if step == failAt {
+				return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: syscall.EIO}
+			}
+			return os.Rename(oldPath, newPath)
+		}
+
+		err := renameInTwoPhases(rotation)
+		phaseRename = os.Rename
+		if err == nil {
+			t.Fatalf("step %d: injected failure was not reported", failAt)
+		}
+
+		for name, want := range files {
+			if got := readTestFile(t, dir, name); got != want {
+				t.Errorf("step %d: %s = %q, want %q", failAt, name, got, want)
+			}
+		}
+		if got := listTestFiles(t, dir); !equalStrings(got, []string{"a", "b", "c"}) {
+			t.Errorf("step %d: files = %v", failAt, got)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Backups named like report.txt.20240605 or report.txt.20240605153000
+var backupSuffix = regexp.MustCompile(`^(.+)\.(\d{14}|\d{8})$`)
+
+// Function to split a backup name into the original name and its timestamp
+func parseBackupName(name string) (string, time.Time, bool) {
+
+	match := backupSuffix.FindStringSubmatch(name)
+	if match == nil {
+		return "", time.Time{}, false
+	}
+
+	layout := "20060102"
+	if len(match[2]) == 14 {
+		layout = "20060102150405"
+	}
+
+	stamp, err := time.Parse(layout, match[2])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	return match[1], stamp, true
+}
+
+// Function to restore the latest timestamped backup of each file to its original name
+func restoreLatestBackup(folderPath string) ([]RenameResult, error) {
+	return restoreLatestBackupWithOptions(folderPath, Options{})
+}
+
+// Function to restore latest backups; with opts.KeepBackup the backup is copied
+// rather than renamed. Originals that still exist are reported and left alone.
+// Restores are recorded in opts.Manifest.
+func restoreLatestBackupWithOptions(folderPath string, opts Options) ([]RenameResult, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	type backup struct {
+		name  string
+		stamp time.Time
+	}
+	latest := make(map[string]backup)
+	for _, file := range files {
+		original, stamp, ok := parseBackupName(file.Name())
+		if !ok {
+			continue
+		}
+		if current, seen := latest[original]; !seen || stamp.After(current.stamp) {
+			latest[original] = backup{file.Name(), stamp}
+		}
+	}
+
+	originals := make([]string, 0, len(latest))
+	for original := range latest {
+		originals = append(originals, original)
+	}
+	sort.Strings(originals)
+
+	results := []RenameResult{}
+	for _, original := range originals {
+
+		result := RenameResult{
+			OldPath: filepath.Join(folderPath, latest[original].name),
+			NewPath: filepath.Join(folderPath, original),
+			Status:  statusPlanned,
+		}
+
+		if _, err := os.Lstat(result.NewPath); err == nil {
+			result.Status = statusSkipped
+			result.Reason = "original already exists"
+			results = append(results, result)
+			continue
+		}
+
+		if !opts.DryRun {
+			var err error
+			if opts.KeepBackup {
+				err = copyFile(result.OldPath, result.NewPath)
+			} else {
+				err = os.Rename(result.OldPath, result.NewPath)
+			}
+
+			if err != nil {
+				result.Status = statusFailed
+				result.Reason = err.Error()
+			} else {
+				result.Status = statusRenamed
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	opts.Manifest.recordResults(operationRename, results)
+
+	return results, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreLatestBackupPicksNewest(t *testing.T) {
+
+	for _, keep := range []bool{false, true} {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{
+			"report.txt.20240101":       "jan",
+			"report.txt.20240605":       "june",
+			"report.txt.20240605093000": "june, later that day",
+			"notes.md.20231231":         "old notes",
+			"notes.md.20240102":         "new notes",
+			"photo.jpg":                 "current photo",
+			"photo.jpg.20240301":        "backup photo",
+			"data.20241301":             "not a real date",
+		})
+
+		results, err := restoreLatestBackupWithOptions(dir, Options{KeepBackup: keep})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("keep %v: results = %+v, want 3", keep, results)
+		}
+
+		byOriginal := make(map[string]RenameResult)
+		for _, result := range results {
+			byOriginal[filepath.Base(result.NewPath)] = result
+		}
+		if r := byOriginal["report.txt"]; r.Status != statusRenamed || filepath.Base(r.OldPath) != "report.txt.20240605093000" {
+			t.Errorf("keep %v: report.txt = %+v", keep, r)
+		}
+		if r := byOriginal["notes.md"]; r.Status != statusRenamed || filepath.Base(r.OldPath) != "notes.md.20240102" {
+			t.Errorf("keep %v: notes.md = %+v", keep, r)
+		}
+		if r := byOriginal["photo.jpg"]; r.Status != statusSkipped || r.Reason != "original already exists" {
+			t.Errorf("keep %v: photo.jpg = %+v", keep, r)
+		}
+
+		if got := readTestFile(t, dir, "report.txt"); got != "june, later that day" {
+			t.Errorf("keep %v: report.txt = %q", keep, got)
+		}
+		if got := readTestFile(t, dir, "photo.jpg"); got != "current photo" {
+			t.Errorf("keep %v: photo.jpg was overwritten with %q", keep, got)
+		}
+
+		// Older backups are never touched; the restored one stays only when kept
+		files := listTestFiles(t, dir)
+		want := []string{"data.20241301", "notes.md", "notes.md.20231231", "photo.jpg", "photo.jpg.20240301", "report.txt", "report.txt.20240101", "report.txt.20240605"}
+		if keep {
+			want = []string{"data.20241301", "notes.md", "notes.md.20231231", "notes.md.20240102", "photo.jpg", "photo.jpg.20240301", "report.txt", "report.txt.20240101", "report.txt.20240605", "report.txt.20240605093000"}
+		}
+		if !equalStrings(files, want) {
+			t.Errorf("keep %v: files = %v, want %v", keep, files, want)
+		}
+	}
+}
+
+func TestRestoreLatestBackupDryRun(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt.20240101": "a"})
+
+	results, err := restoreLatestBackupWithOptions(dir, Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != statusPlanned {
+		t.Errorf("results = %+v", results)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.txt.20240101"}) {
+		t.Errorf("dry run changed files: %v", got)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// RenameRule changes OldExt to NewExt for files whose name also matches the
+// optional Match glob (for example "IMG_*")
+type RenameRule struct {
+	OldExt string `json:"oldExt"`
+	NewExt string `json:"newExt"`
+	Match  string `json:"match,omitempty"`
+}
+
+// RuleManifest is a JSON file listing several rename rules:
+//
+//	{"rules": [{"oldExt": "jpeg", "newExt": "jpg"}, {"oldExt": "jpg", "newExt": "png", "match": "icon_*"}]}
+type RuleManifest struct {
+	Rules []RenameRule `json:"rules"`
+}
+
+// RuleMatches lists the files one rule would rename
+type RuleMatches struct {
+	Rule  RenameRule `json:"rule"`
+	Files []string   `json:"files"`
+}
+
+// ManifestExplanation is what a rule manifest would do to a folder
+type ManifestExplanation struct {
+	Rules []RuleMatches `json:"rules"`
+	// Overlaps maps files matched by more than one rule to those rules' indexes
+	Overlaps map[string][]int `json:"overlaps"`
+}
+
+// Function to load and validate a rule manifest
+func loadRuleManifest(manifestPath string) (*RuleManifest, error) {
+
+	data, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest RuleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %v", manifestPath, err)
+	}
+
+	for i, rule := range manifest.Rules {
+		if rule.OldExt == "" || rule.NewExt == "" {
+			return nil, fmt.Errorf("%s: rule %d needs oldExt and newExt", manifestPath, i)
+		}
+		if _, err := filepath.Match(rule.Match, ""); err != nil {
+			return nil, fmt.Errorf("%s: rule %d: bad match pattern %q", manifestPath, i, rule.Match)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// Function to tell whether a rule applies to a file name
+func ruleMatches(rule RenameRule, name string) bool {
+
+	if !strings.HasSuffix(name, normalizeExt(rule.OldExt)) {
+		return false
+	}
+	if rule.Match == "" {
+		return true
+	}
+
+	matched, _ := filepath.Match(rule.Match, name)
+	return matched
+}
+
+// Function to show, per rule, which files it would rename, without renaming anything
+func explainManifest(manifestPath string, folderPath string) (*ManifestExplanation, error) {
+
+	manifest, err := loadRuleManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := captureSnapshot(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &ManifestExplanation{Overlaps: make(map[string][]int)}
+	matchedBy := make(map[string][]int)
+	for i, rule := range manifest.Rules {
+
+		matches := RuleMatches{Rule: rule, Files: []string{}}
+		for _, entry := range snapshot.Files {
+			if ruleMatches(rule, entry.Path) {
+				matches.Files = append(matches.Files, entry.Path)
+				matchedBy[entry.Path] = append(matchedBy[entry.Path], i)
+			}
+		}
+
+		explanation.Rules = append(explanation.Rules, matches)
+	}
+
+	for file, rules := range matchedBy {
+		if len(rules) > 1 {
+			explanation.Overlaps[file] = rules
+		}
+	}
+
+	return explanation, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExplainManifestOverlappingRules(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"IMG_1.jpeg": "a",
+		"IMG_2.jpeg": "b",
+		"photo.jpeg": "c",
+		"notes.md":   "d",
+		"other.txt":  "e",
+	})
+
+	manifestPath := filepath.Join(t.TempDir(), "rules.json")
+	manifest := `{"rules": [
+		{"oldExt": "jpeg", "newExt": "jpg"},
+		{"oldExt": ".jpeg", "newExt": "png", "match": "IMG_*"},
+		{"oldExt": "md", "newExt": "txt"}
+	]}`
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	explanation, err := explainManifest(manifestPath, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantFiles := [][]string{
+		{"IMG_1.jpeg", "IMG_2.jpeg", "photo.jpeg"},
+		{"IMG_1.jpeg", "IMG_2.jpeg"},
+		{"notes.md"},
+	}
+	if len(explanation.Rules) != len(wantFiles) {
+		t.Fatalf("rules = %+v", explanation.Rules)
+	}
+	for i, want := range wantFiles {
+		if !equalStrings(explanation.Rules[i].Files, want) {
+			t.Errorf("rule %d files = %v, want %v", i, explanation.Rules[i].Files, want)
+		}
+	}
+
+	wantOverlaps := map[string][]int{"IMG_1.jpeg": {0, 1}, "IMG_2.jpeg": {0, 1}}
+	if !reflect.DeepEqual(explanation.Overlaps, wantOverlaps) {
+		t.Errorf("overlaps = %v, want %v", explanation.Overlaps, wantOverlaps)
+	}
+
+	// Explaining never renames anything
+	if got := listTestFiles(t, dir); len(got) != 5 {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestLoadRuleManifestRejectsBadRules(t *testing.T) {
+
+	dir := t.TempDir()
+	for name, manifest := range map[string]string{
+		"missing.json": `{"rules": [{"oldExt": "jpeg"}]}`,
+		"pattern.json": `{"rules": [{"oldExt": "jpeg", "newExt": "jpg", "match": "[IMG"}]}`,
+		"syntax.json":  `{"rules": [`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadRuleManifest(path); err == nil {
+			t.Errorf("%s was accepted", name)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// How long to wait for another process to release the counter
+const counterLockTimeout = 5 * time.Second
+
+// A lock is only held for one read-increment-write, so one this old was left
+// behind by a process that crashed while holding it
+const lockStaleAfter = time.Minute
+
+// LockTimeoutError means another process held the lock for the whole wait
+type LockTimeoutError struct {
+	Path   string
+	Waited time.Duration
+}
+
+func (e *LockTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %v waiting for lock %s (held by another process, or stale)", e.Waited, e.Path)
+}
+
+// Function to take an exclusive lock by creating lockPath, retrying until timeout.
+// A lock file older than lockStaleAfter is taken to be abandoned and removed.
+func acquireLock(lockPath string, timeout time.Duration) (func(), error) {
+
+	start := time.Now()
+	for {
+		file, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if removeStaleLock(lockPath) {
+			continue
+		}
+
+		if waited := time.Since(start); waited >= timeout {
+			return nil, &LockTimeoutError{Path: lockPath, Waited: waited}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Function to remove lockPath if it is older than lockStaleAfter. Breaking a
+// lock is itself done under lockPath+".break", and the lock is looked at again
+// once that is held, so two processes that both saw the same stale lock can't
+// end up with one of them removing the fresh lock the other just created.
+func removeStaleLock(lockPath string) bool {
+
+	if info, err := os.Stat(lockPath); err != nil || time.Since(info.ModTime()) <= lockStaleAfter {
+		return false
+	}
+
+	breakPath := lockPath + ".break"
+	file, err := os.OpenFile(breakPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		// Someone else is already breaking it
+		return false
+	}
+	file.Close()
+	defer os.Remove(breakPath)
+
+	info, err := os.Stat(lockPath)
+	if err != nil {
+		// Gone already, so it is free to take
+		return os.IsNotExist(err)
+	}
+	if time.Since(info.ModTime()) <= lockStaleAfter {
+		return false
+	}
+
+	fmt.Printf("Removing stale lock %s\n", lockPath)
+	return os.Remove(lockPath) == nil
+}
+
+// Function to read, increment and save the number in a shared counter file,
+// returning the number reserved. Safe across goroutines and processes.
+func allocateSequence(counterPath string, timeout time.Duration) (int, error) {
+
+	release, err := acquireLock(counterPath+".lock", timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	current := 0
+	data, err := ioutil.ReadFile(counterPath)
+	switch {
+	case err == nil:
+		if current, err = strconv.Atoi(strings.TrimSpace(string(data))); err != nil {
+			return 0, fmt.Errorf("counter file %s is corrupt: %v", counterPath, err)
+		}
+	case !os.IsNotExist(err):
+		return 0, err
+	}
+
+	next := current + 1
+
+	// Write then rename, so a crash never leaves a half-written counter
+	tmp := counterPath + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.Itoa(next)+"\n"), 0644); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, counterPath); err != nil {
+		return 0, err
+	}
+
+	return next, nil
+}
+
+// Function to return the file listing every name renameWithSequence has
+// given out from the counter at counterPath
+func sequenceNamesPath(counterPath string) string {
+	return counterPath + ".names"
+}
+
+// Function to load the names given out from the counter at counterPath
+func loadSequenceNames(counterPath string) (map[string]bool, error) {
+
+	names := make(map[string]bool)
+	data, err := ioutil.ReadFile(sequenceNamesPath(counterPath))
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			names[line] = true
+		}
+	}
+
+	return names, nil
+}
+
+// Function to add a name to the list given out from the counter at counterPath
+func recordSequenceName(counterPath string, name string, timeout time.Duration) error {
+
+	release, err := acquireLock(counterPath+".lock", timeout)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	file, err := os.OpenFile(sequenceNamesPath(counterPath), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(file, name); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Function to rename files with numbers reserved from a shared counter file,
+// so several machines feeding one folder never reuse a number. The template
+// gets {{.Index}} (the reserved number), {{.Stem}} and {{.Ext}}. Every name
+// given out is listed next to the counter, and files carrying one of those
+// names are left alone, so repeated runs only number new arrivals. A name
+// that merely looks numbered, like a camera's IMG_1234.jpg, is still renamed.
+// The counter and its side files are skipped when they live in the folder.
+func renameWithSequence(folderPath string, counterPath string, tmpl string) (map[string]string, error) {
+	return renameWithSequenceWithOptions(folderPath, counterPath, tmpl, Options{})
+}
+
+// Function to rename from a shared counter, recording renames in opts.Manifest
+func renameWithSequenceWithOptions(folderPath string, counterPath string, tmpl string, opts Options) (map[string]string, error) {
+
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	counterFiles := make(map[string]bool)
+	for _, path := range []string{counterPath, counterPath + ".lock", counterPath + ".lock.break", counterPath + ".tmp", sequenceNamesPath(counterPath)} {
+		if abs, err := filepath.Abs(path); err == nil {
+			counterFiles[abs] = true
+		}
+	}
+
+	numbered, err := loadSequenceNames(counterPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string)
+	for _, file := range files {
+
+		if abs, err := filepath.Abs(filepath.Join(folderPath, file.Name())); err == nil && counterFiles[abs] {
+			continue
+		}
+
+		if numbered[file.Name()] {
+			continue
+		}
+
+		ext := filepath.Ext(file.Name())
+
+		number, err := allocateSequence(counterPath, counterLockTimeout)
+		if err != nil {
+			return mapping, err
+		}
+
+		newName, err := executeNameTemplate(t, templateData{
+			Index: number,
+			Total: len(files),
+			Stem:  strings.TrimSuffix(file.Name(), ext),
+			Ext:   ext,
+		})
+		if err != nil {
+			return mapping, fmt.Errorf("%s: %v", file.Name(), err)
+		}
+
+		oldPath := filepath.Join(folderPath, file.Name())
+		newPath := filepath.Join(folderPath, newName)
+		if _, err := os.Lstat(newPath); err == nil {
+			return mapping, fmt.Errorf("cannot rename %s to %s: file exists", oldPath, newPath)
+		}
+		if err := os.Rename(oldPath, newPath); err != nil {
+			opts.Manifest.record(operationRename, oldPath, newPath, statusFailed)
+			return mapping, err
+		}
+		opts.Manifest.record(operationRename, oldPath, newPath, statusRenamed)
+		mapping[file.Name()] = newName
+
+		if err := recordSequenceName(counterPath, newName, counterLockTimeout); err != nil {
+			return mapping, err
+		}
+	}
+
+	return mapping, nil
+}
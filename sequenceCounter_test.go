@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAllocateSequenceTwoGoroutines(t *testing.T) {
+
+	counterPath := filepath.Join(t.TempDir(), "counter")
+
+	const perGoroutine = 50
+	var mu sync.Mutex
+	var numbers []int
+	var wg sync.WaitGroup
+	for g := 0; g < 2; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				number, err := allocateSequence(counterPath, counterLockTimeout)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				numbers = append(numbers, number)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every number from 1 up is handed out exactly once
+	sort.Ints(numbers)
+	if len(numbers) != 2*perGoroutine {
+		t.Fatalf("got %d numbers, want %d", len(numbers), 2*perGoroutine)
+	}
+	for i, number := range numbers {
+		if number != i+1 {
+			t.Fatalf("numbers[%d] = %d: duplicated or skipped", i, number)
+		}
+	}
+	if got := readTestFile(t, filepath.Dir(counterPath), "counter"); got != "100\n" {
+		t.Errorf("counter = %q", got)
+	}
+}
+
+func TestAcquireLockContentionAndStaleLock(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"counter.lock": "4242\n"})
+	lockPath := filepath.Join(dir, "counter.lock")
+
+	// A fresh lock held by someone else times out
+	_, err := acquireLock(lockPath, 50*time.Millisecond)
+	var timeout *LockTimeoutError
+	if !errors.As(err, &timeout) || timeout.Path != lockPath {
+		t.Fatalf("err = %v, want a LockTimeoutError", err)
+	}
+
+	// One left behind long ago is cleared
+	backdate(t, dir, "counter.lock", time.Now().Add(-2*lockStaleAfter))
+	release, err := acquireLock(lockPath, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("stale lock was not recovered: %v", err)
+	}
+	release()
+	if got := listTestFiles(t, dir); len(got) != 0 {
+		t.Errorf("files after release = %v", got)
+	}
+}
+
+func TestStaleLockIsBrokenOnce(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"counter.lock": "4242\n"})
+	backdate(t, dir, "counter.lock", time.Now().Add(-2*lockStaleAfter))
+	lockPath := filepath.Join(dir, "counter.lock")
+
+	// Everyone sees the same stale lock at once; only one may hold it at a time
+	var holders int32
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := acquireLock(lockPath, counterLockTimeout)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if atomic.AddInt32(&holders, 1) > 1 {
+				t.Error("two holders of one lock")
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+			release()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRemoveStaleLockLeavesFreshLock(t *testing.T) {
+
+	// A breaker that saw the stale lock but lost the race finds a fresh one in its place
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"counter.lock": "4242\n"})
+	lockPath := filepath.Join(dir, "counter.lock")
+
+	if removeStaleLock(lockPath) {
+		t.Error("a fresh lock was removed")
+	}
+
+	// And one breaking the lock already holds off the others
+	backdate(t, dir, "counter.lock", time.Now().Add(-2*lockStaleAfter))
+	writeTestFiles(t, dir, map[string]string{"counter.lock.break": ""})
+	if removeStaleLock(lockPath) {
+		t.Error("a stale lock was removed while another process was breaking it")
+	}
+
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"counter.lock", "counter.lock.break"}) {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestRenameWithSequenceRenamesLookalikes(t *testing.T) {
+
+	// A camera name looks numbered by the template but wasn't numbered by us
+	dir := t.TempDir()
+	counterPath := filepath.Join(t.TempDir(), "counter")
+	tmpl := "{{.Stem}}_{{.Index}}{{.Ext}}"
+	writeTestFiles(t, dir, map[string]string{"IMG_1234.jpg": "a", "holiday.jpg": "b"})
+
+	mapping, err := renameWithSequence(dir, counterPath, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapping) != 2 || mapping["IMG_1234.jpg"] != "IMG_1234_1.jpg" || mapping["holiday.jpg"] != "holiday_2.jpg" {
+		t.Errorf("first run mapping = %v", mapping)
+	}
+
+	// Its new name is ours, so the next run leaves it alone
+	mapping, err = renameWithSequence(dir, counterPath, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapping) != 0 {
+		t.Errorf("second run mapping = %v", mapping)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"IMG_1234_1.jpg", "holiday_2.jpg"}) {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestRenameWithSequenceOnlyNumbersNewFiles(t *testing.T) {
+
+	dir := t.TempDir()
+	counterPath := filepath.Join(dir, "counter")
+	tmpl := `ingest_{{printf "%04d" .Index}}{{.Ext}}`
+	writeTestFiles(t, dir, map[string]string{"a.jpg": "a", "b.png": "b"})
+
+	mapping, err := renameWithSequence(dir, counterPath, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapping) != 2 || mapping["a.jpg"] != "ingest_0001.jpg" || mapping["b.png"] != "ingest_0002.png" {
+		t.Errorf("first run mapping = %v", mapping)
+	}
+
+	// The second run leaves the numbered files and the counter alone
+	writeTestFiles(t, dir, map[string]string{"c.jpg": "c"})
+	mapping, err = renameWithSequence(dir, counterPath, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapping) != 1 || mapping["c.jpg"] != "ingest_0003.jpg" {
+		t.Errorf("second run mapping = %v", mapping)
+	}
+
+	want := []string{"counter", "counter.names", "ingest_0001.jpg", "ingest_0002.png", "ingest_0003.jpg"}
+	if got := listTestFiles(t, dir); !equalStrings(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
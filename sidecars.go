@@ -0,0 +1,88 @@
+package main
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Function to lower-case and dot-prefix a list of extensions
+func extensionSet(exts []string) map[string]bool {
+
+	set := make(map[string]bool)
+	for _, ext := range exts {
+		set[strings.ToLower(normalizeExt(ext))] = true
+	}
+
+	return set
+}
+
+// Function to list sidecar files (like .xmp or .srt) whose main file is gone
+func findOrphanedSidecars(folderPath string, sidecarExts []string, mainExts []string) ([]string, error) {
+	return findOrphanedSidecarsWithOptions(folderPath, sidecarExts, mainExts, Options{})
+}
+
+// Function to list orphaned sidecars, optionally recursively, as paths relative to folderPath.
+// Both "photo.xmp" and "photo.jpg.xmp" count as sidecars of "photo.jpg".
+func findOrphanedSidecarsWithOptions(folderPath string, sidecarExts []string, mainExts []string, opts Options) ([]string, error) {
+
+	snapshot, err := scanSnapshot(folderPath, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	sidecars := extensionSet(sidecarExts)
+	mains := extensionSet(mainExts)
+
+	// Main files keyed by lower-cased stem, so "photo.JPG" matches "photo.xmp"
+	mainStems := make(map[string]bool)
+	mainFiles := make(map[string]bool)
+	for _, entry := range snapshot.Files {
+		ext := strings.ToLower(path.Ext(entry.Path))
+		if mains[ext] {
+			mainStems[strings.ToLower(strings.TrimSuffix(entry.Path, path.Ext(entry.Path)))] = true
+			mainFiles[strings.ToLower(entry.Path)] = true
+		}
+	}
+
+	orphans := []string{}
+	for _, entry := range snapshot.Files {
+
+		ext := strings.ToLower(path.Ext(entry.Path))
+		if !sidecars[ext] {
+			continue
+		}
+
+		stem := strings.ToLower(strings.TrimSuffix(entry.Path, path.Ext(entry.Path)))
+		if mainStems[stem] || mainFiles[stem] {
+			continue
+		}
+
+		orphans = append(orphans, entry.Path)
+	}
+
+	return orphans, nil
+}
+
+// Function to delete orphaned sidecars (to the trash with opts.UseTrash), returning the ones removed
+func removeOrphanedSidecars(folderPath string, sidecarExts []string, mainExts []string, opts Options) ([]string, error) {
+
+	orphans, err := findOrphanedSidecarsWithOptions(folderPath, sidecarExts, mainExts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		return orphans, nil
+	}
+
+	removed := []string{}
+	for _, orphan := range orphans {
+		if err := removeFile(filepath.Join(folderPath, filepath.FromSlash(orphan)), opts); err != nil {
+			return removed, err
+		}
+		removed = append(removed, orphan)
+	}
+
+	return removed, nil
+}
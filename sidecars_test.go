@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFindOrphanedSidecars(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"photo.JPG": "", "photo.xmp": "",
+		"raw.cr2": "", "raw.cr2.xmp": "",
+		"gone.xmp":     "",
+		"gone.jpg.xmp": "",
+		"movie.mp4":    "", "movie.srt": "",
+		"lost.SRT":    "",
+		"notes.txt":   "",
+		"sub/pic.jpg": "", "sub/pic.xmp": "",
+		"sub/orphan.xmp": "",
+	})
+	sidecarExts := []string{"xmp", ".srt"}
+	mainExts := []string{"jpg", "cr2", "mp4"}
+
+	orphans, err := findOrphanedSidecars(dir, sidecarExts, mainExts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"gone.jpg.xmp", "gone.xmp", "lost.SRT"}; !equalStrings(orphans, want) {
+		t.Errorf("orphans = %v, want %v", orphans, want)
+	}
+
+	orphans, err = findOrphanedSidecarsWithOptions(dir, sidecarExts, mainExts, Options{Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"gone.jpg.xmp", "gone.xmp", "lost.SRT", "sub/orphan.xmp"}; !equalStrings(orphans, want) {
+		t.Errorf("recursive orphans = %v, want %v", orphans, want)
+	}
+}
+
+func TestRemoveOrphanedSidecars(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"keep.jpg": "", "keep.xmp": "", "drop.xmp": ""})
+
+	removed, err := removeOrphanedSidecars(dir, []string{"xmp"}, []string{"jpg"}, Options{DryRun: true})
+	if err != nil || !equalStrings(removed, []string{"drop.xmp"}) {
+		t.Fatalf("dry run = %v, %v", removed, err)
+	}
+	if len(listTestFiles(t, dir)) != 3 {
+		t.Error("dry run removed files")
+	}
+
+	removed, err = removeOrphanedSidecars(dir, []string{"xmp"}, []string{"jpg"}, Options{})
+	if err != nil || !equalStrings(removed, []string{"drop.xmp"}) {
+		t.Fatalf("removeOrphanedSidecars = %v, %v", removed, err)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"keep.jpg", "keep.xmp"}) {
+		t.Errorf("files = %v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// SnapshotEntry is one file in a captured directory listing
+type SnapshotEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Hash    string    `json:"hash,omitempty"`
+}
+
+// Snapshot is a directory listing that can be saved and planned against later
+type Snapshot struct {
+	Root  string          `json:"root"`
+	Files []SnapshotEntry `json:"files"`
+}
+
+// Function to capture the files of a folder into a snapshot
+func captureSnapshot(folderPath string) (*Snapshot, error) {
+	return scanSnapshot(folderPath, false)
+}
+
+// Function to capture a folder, and optionally everything below it, into a snapshot
+func scanSnapshot(folderPath string, recursive bool) (*Snapshot, error) {
+
+	// os.DirFS("") would be the filesystem root, so check the folder first
+	if _, err := os.Stat(folderPath); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := scanFS(os.DirFS(folderPath), recursive)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Root = folderPath
+
+	return snapshot, nil
+}
+
+// Function to save a snapshot as JSON
+func writeSnapshot(snapshot *Snapshot, path string) error {
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Function to load a snapshot saved with writeSnapshot
+func readSnapshot(path string) (*Snapshot, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+
+	// Keep planning deterministic even if the file was edited by hand
+	sort.Slice(snapshot.Files, func(i, j int) bool {
+		return snapshot.Files[i].Path < snapshot.Files[j].Path
+	})
+
+	return &snapshot, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// RenamedFile is a file that moved between two snapshots
+type RenamedFile struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SnapshotDiff is everything that changed between two snapshots of a tree
+type SnapshotDiff struct {
+	Added    []string      `json:"added"`
+	Removed  []string      `json:"removed"`
+	Renamed  []RenamedFile `json:"renamed"`
+	Modified []string      `json:"modified"`
+}
+
+// Function to capture a whole tree, optionally with content hashes, as a
+// rollback point that later changes can be compared against no matter what made them
+func snapshotManifest(root string, withHashes bool) (*Snapshot, error) {
+
+	snapshot, err := scanSnapshot(root, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if withHashes {
+		paths := make([]string, len(snapshot.Files))
+		for i, entry := range snapshot.Files {
+			paths[i] = filepath.Join(root, filepath.FromSlash(entry.Path))
+		}
+
+		hashes, err := hashFiles(paths, hashConcurrency(Options{}))
+		if err != nil {
+			return nil, err
+		}
+		for i := range snapshot.Files {
+			snapshot.Files[i].Hash = hashes[paths[i]]
+		}
+	}
+
+	return snapshot, nil
+}
+
+// Function to tell whether two entries hold the same content: by hash when
+// both have one, otherwise by size and modification time
+func sameContent(a SnapshotEntry, b SnapshotEntry) bool {
+
+	if a.Hash != "" && b.Hash != "" {
+		return a.Hash == b.Hash
+	}
+
+	return a.Size == b.Size && a.ModTime.Equal(b.ModTime)
+}
+
+// Function to compare two snapshots. A file that disappeared and one that
+// appeared with the same content are reported as a rename.
+func diffManifest(before *Snapshot, after *Snapshot) SnapshotDiff {
+
+	diff := SnapshotDiff{Added: []string{}, Removed: []string{}, Renamed: []RenamedFile{}, Modified: []string{}}
+
+	beforeFiles := make(map[string]SnapshotEntry)
+	for _, entry := range before.Files {
+		beforeFiles[entry.Path] = entry
+	}
+	afterFiles := make(map[string]SnapshotEntry)
+	for _, entry := range after.Files {
+		afterFiles[entry.Path] = entry
+	}
+
+	var removed, added []SnapshotEntry
+	for _, entry := range before.Files {
+		now, ok := afterFiles[entry.Path]
+		switch {
+		case !ok:
+			removed = append(removed, entry)
+		case !sameContent(entry, now):
+			diff.Modified = append(diff.Modified, entry.Path)
+		}
+	}
+	for _, entry := range after.Files {
+		if _, ok := beforeFiles[entry.Path]; !ok {
+			added = append(added, entry)
+		}
+	}
+
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Path < removed[j].Path })
+	sort.Slice(added, func(i, j int) bool { return added[i].Path < added[j].Path })
+
+	matched := make(map[int]bool)
+	for _, gone := range removed {
+		renamed := false
+		for i, appeared := range added {
+			if !matched[i] && sameContent(gone, appeared) {
+				matched[i] = true
+				diff.Renamed = append(diff.Renamed, RenamedFile{From: gone.Path, To: appeared.Path})
+				renamed = true
+				break
+			}
+		}
+		if !renamed {
+			diff.Removed = append(diff.Removed, gone.Path)
+		}
+	}
+	for i, appeared := range added {
+		if !matched[i] {
+			diff.Added = append(diff.Added, appeared.Path)
+		}
+	}
+
+	sort.Strings(diff.Modified)
+
+	return diff
+}
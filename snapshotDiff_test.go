@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffManifestAfterChanges(t *testing.T) {
+
+	for _, withHashes := range []bool{true, false} {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{
+			"keep.txt":       "unchanged",
+			"move_me.txt":    "moving content",
+			"edit.txt":       "before",
+			"gone.txt":       "deleted later",
+			"sub/nested.txt": "nested move",
+		})
+		stamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		for _, name := range []string{"keep.txt", "move_me.txt", "edit.txt", "gone.txt", "sub/nested.txt"} {
+			backdate(t, dir, name, stamp)
+		}
+
+		before, err := snapshotManifest(dir, withHashes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Rename, move across folders, edit in place (same size), delete and add
+		if err := os.Rename(filepath.Join(dir, "move_me.txt"), filepath.Join(dir, "moved.txt")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(filepath.Join(dir, "sub", "nested.txt"), filepath.Join(dir, "nested.txt")); err != nil {
+			t.Fatal(err)
+		}
+		writeTestFiles(t, dir, map[string]string{"edit.txt": "after!", "new.txt": "brand new"})
+		backdate(t, dir, "edit.txt", stamp.Add(time.Hour))
+		if err := os.Remove(filepath.Join(dir, "gone.txt")); err != nil {
+			t.Fatal(err)
+		}
+
+		after, err := snapshotManifest(dir, withHashes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := SnapshotDiff{
+			Added:    []string{"new.txt"},
+			Removed:  []string{"gone.txt"},
+			Renamed:  []RenamedFile{{From: "move_me.txt", To: "moved.txt"}, {From: "sub/nested.txt", To: "nested.txt"}},
+			Modified: []string{"edit.txt"},
+		}
+		if got := diffManifest(before, after); !reflect.DeepEqual(got, want) {
+			t.Errorf("hashes %v: diff = %+v, want %+v", withHashes, got, want)
+		}
+
+		if got := diffManifest(after, after); len(got.Added)+len(got.Removed)+len(got.Renamed)+len(got.Modified) != 0 {
+			t.Errorf("hashes %v: self diff = %+v", withHashes, got)
+		}
+	}
+}
+
+func TestDiffManifestHashesCatchSameSizeEdits(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "aaaa"})
+	stamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	backdate(t, dir, "a.txt", stamp)
+
+	before, err := snapshotManifest(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same size and restored mtime: only the hash tells them apart
+	writeTestFiles(t, dir, map[string]string{"a.txt": "bbbb"})
+	backdate(t, dir, "a.txt", stamp)
+
+	after, err := snapshotManifest(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := diffManifest(before, after); !equalStrings(got.Modified, []string{"a.txt"}) {
+		t.Errorf("diff = %+v, want a.txt modified", got)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"b.txt": "bb", "a.txt": "a", "sub/c.txt": "ccc"})
+
+	flat, err := captureSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flat.Files) != 2 {
+		t.Errorf("non-recursive snapshot has %d files, want 2", len(flat.Files))
+	}
+
+	snapshot, err := scanSnapshot(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, entry := range snapshot.Files {
+		paths = append(paths, entry.Path)
+	}
+	if want := []string{"a.txt", "b.txt", "sub/c.txt"}; !equalStrings(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+
+	saved := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := writeSnapshot(snapshot, saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := readSnapshot(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Root != snapshot.Root || len(loaded.Files) != len(snapshot.Files) {
+		t.Fatalf("loaded %+v, want %+v", loaded, snapshot)
+	}
+	for i := range loaded.Files {
+		if !loaded.Files[i].ModTime.Equal(snapshot.Files[i].ModTime) {
+			t.Errorf("mod time of %s changed", loaded.Files[i].Path)
+		}
+		loaded.Files[i].ModTime = snapshot.Files[i].ModTime
+	}
+	if !reflect.DeepEqual(loaded, snapshot) {
+		t.Errorf("loaded %+v, want %+v", loaded, snapshot)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var errStagingAborted = errors.New("staged run aborted, nothing was changed")
+
+// stageCopy is swapped out to simulate a copy failing while staging
+var stageCopy = copyFile
+
+// Function to carry out a plan all-or-nothing: every file is first copied into
+// a staging folder and verified, and only when all copies are good are they
+// moved into place and the originals removed. This costs a full copy of
+// every file but, unlike renaming in place, a failure while staging leaves
+// the real folder exactly as it was. opts.Limit and opts.Progress work as in
+// applyPlan; Workers is ignored since the staging copies run one at a time.
+func applyPlanStaged(plan []RenameResult, root string, opts Options) ([]RenameResult, error) {
+
+	results := make([]RenameResult, len(plan))
+	copy(results, plan)
+
+	// A staged run either renames everything or nothing, so the first
+	// opts.Limit planned entries are exactly the ones that get renamed
+	if opts.Limit > 0 {
+		planned := 0
+		for i := range results {
+			if results[i].Status != statusPlanned {
+				continue
+			}
+			planned++
+			if planned > opts.Limit {
+				skipForLimit(&results[i], opts.Limit)
+			}
+		}
+	}
+
+	abort := func(err error) ([]RenameResult, error) {
+		for i := range results {
+			if results[i].Status == statusPlanned {
+				results[i].Status = statusFailed
+				results[i].Reason = "staging aborted: " + err.Error()
+			}
+		}
+		return results, fmt.Errorf("%w: %v", errStagingAborted, err)
+	}
+
+	stagingDir, err := ioutil.TempDir(root, ".fmstage-")
+	if err != nil {
+		return abort(err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	// Stage and verify every file before touching anything real
+	staged := make(map[int]string)
+	for i, result := range results {
+
+		if result.Status != statusPlanned {
+			continue
+		}
+
+		if _, err := os.Lstat(result.NewPath); err == nil {
+			return abort(fmt.Errorf("%s already exists", result.NewPath))
+		}
+
+		stagedPath := filepath.Join(stagingDir, fmt.Sprintf("%d", i))
+		if err := stageCopy(result.OldPath, stagedPath); err != nil {
+			return abort(err)
+		}
+		if err := verifySameContent(result.OldPath, stagedPath); err != nil {
+			return abort(err)
+		}
+		staged[i] = stagedPath
+	}
+
+	// Swap the staged copies into place, undoing them all if one fails
+	var placed []string
+	for i, result := range results {
+
+		stagedPath, ok := staged[i]
+		if !ok {
+			continue
+		}
+
+		err := os.MkdirAll(filepath.Dir(result.NewPath), 0755)
+		if err == nil {
+			err = renameOrCopy(stagedPath, result.NewPath, opts)
+		}
+		if err != nil {
+			for _, path := range placed {
+				os.Remove(path)
+			}
+			return abort(err)
+		}
+		placed = append(placed, result.NewPath)
+	}
+
+	// Everything is in place; the originals can go
+	progress := newProgressCounter(results, opts)
+	for i := range results {
+
+		if _, ok := staged[i]; !ok {
+			continue
+		}
+
+		if err := os.Remove(results[i].OldPath); err != nil {
+			results[i].Status = statusFailed
+			results[i].Reason = "renamed copy committed but original not removed: " + err.Error()
+		} else {
+			results[i].Status = statusRenamed
+		}
+		progress.step()
+	}
+
+	return results, nil
+}
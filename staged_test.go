@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyPlanStaged(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+
+	var calls [][2]int
+	opts := Options{Staged: true, Progress: func(done int, total int) { calls = append(calls, [2]int{done, total}) }}
+	results, err := changeFileExtensionsWithOptions("txt", "md", dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if countStatus(results, statusRenamed) != 3 {
+		t.Errorf("results = %+v", results)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.md", "b.md", "c.md"}) {
+		t.Errorf("files = %v", got)
+	}
+	if len(calls) != 3 || calls[2] != [2]int{3, 3} {
+		t.Errorf("progress calls = %v", calls)
+	}
+}
+
+func TestApplyPlanStagedCopyFailureChangesNothing(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+
+	// The second copy fails after the first was already staged
+	copies := 0
+	stageCopy = func(src string, dst string) error {
+		copies++
+		if copies == 2 {
+			return errors.New("injected copy failure")
+		}
+		return copyFile(src, dst)
+	}
+	t.Cleanup(func() { stageCopy = copyFile })
+
+	results, err := changeFileExtensionsWithOptions("txt", "md", dir, Options{Staged: true})
+	if !errors.Is(err, errStagingAborted) {
+		t.Fatalf("err = %v, want errStagingAborted", err)
+	}
+	if countStatus(results, statusFailed) != 3 {
+		t.Errorf("results = %+v", results)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.txt", "b.txt", "c.txt"}) {
+		t.Errorf("files after aborted staging = %v", got)
+	}
+}
+
+func TestApplyPlanStagedSwapFailureKeepsOriginals(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b", "blocker": "a regular file"})
+
+	// b's target sits under a regular file, so it can't be placed
+	plan := []RenameResult{
+		plannedUnder(dir, "a.txt", "a.md"),
+		plannedUnder(dir, "b.txt", "blocker/b.md"),
+	}
+
+	results, err := applyPlanStaged(plan, dir, Options{})
+	if !errors.Is(err, errStagingAborted) {
+		t.Fatalf("err = %v, want errStagingAborted", err)
+	}
+	for _, result := range results {
+		if result.Status != statusFailed || !strings.Contains(result.Reason, "staging aborted") {
+			t.Errorf("%s is %s (%s)", filepath.Base(result.OldPath), result.Status, result.Reason)
+		}
+	}
+
+	// Both originals survive and the already placed copy is rolled back
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"a.txt", "b.txt", "blocker"}) {
+		t.Errorf("files after failed swap = %v", got)
+	}
+	if readTestFile(t, dir, "b.txt") != "b" {
+		t.Error("original lost after a failed swap")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Characters that end a word in a file name
+const prefixWordSeparators = " _-."
+
+// Function to find the longest prefix shared by every name, cut back so it
+// ends on a word separator
+func commonWordPrefix(names []string) string {
+
+	if len(names) < 2 {
+		return ""
+	}
+
+	prefix := names[0]
+	for _, name := range names[1:] {
+		for !strings.HasPrefix(name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	cut := strings.LastIndexAny(prefix, prefixWordSeparators)
+	if cut < 0 {
+		return ""
+	}
+
+	return prefix[:cut+1]
+}
+
+// Function to remove the prefix every file name in a folder repeats.
+// Files left with an empty or colliding name keep their current name.
+func stripCommonPrefix(folderPath string) (map[string]string, error) {
+	return stripCommonPrefixWithOptions(folderPath, Options{})
+}
+
+// Function to strip the common prefix, recording renames in opts.Manifest
+func stripCommonPrefixWithOptions(folderPath string, opts Options) (map[string]string, error) {
+
+	files, err := listFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stems := make([]string, len(files))
+	for i, file := range files {
+		stems[i] = strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+	}
+
+	mapping := make(map[string]string)
+	prefix := commonWordPrefix(stems)
+	if prefix == "" {
+		return mapping, nil
+	}
+
+	renaming := make(map[string]string)
+	for i, file := range files {
+
+		stem := strings.TrimLeft(strings.TrimPrefix(stems[i], prefix), prefixWordSeparators)
+		if stem == "" {
+			fmt.Printf("Skipped: %s (nothing left after removing %q)\n", file.Name(), prefix)
+			continue
+		}
+		renaming[file.Name()] = stem + filepath.Ext(file.Name())
+	}
+
+	// A name already on disk is only free when its owner is itself being renamed
+	// out of the way, and dropping one rename can take that away from another,
+	// so repeat until no more renames are dropped
+	for dropped := true; dropped; {
+		dropped = false
+		claimed := make(map[string]bool)
+		for _, file := range files {
+
+			newName, ok := renaming[file.Name()]
+			if !ok {
+				continue
+			}
+
+			_, statErr := os.Lstat(filepath.Join(folderPath, newName))
+			_, vacated := renaming[newName]
+			if claimed[newName] || (statErr == nil && !vacated) {
+				fmt.Printf("Skipped: %s (%s already exists)\n", file.Name(), newName)
+				delete(renaming, file.Name())
+				dropped = true
+				continue
+			}
+			claimed[newName] = true
+		}
+	}
+
+	paths := make(map[string]string)
+	for oldName, newName := range renaming {
+		paths[filepath.Join(folderPath, oldName)] = filepath.Join(folderPath, newName)
+		mapping[oldName] = newName
+	}
+
+	if err := renameInTwoPhases(paths); err != nil {
+		return nil, err
+	}
+	opts.Manifest.recordPaths(operationRename, paths)
+
+	return mapping, nil
+}
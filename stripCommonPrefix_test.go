@@ -0,0 +1,94 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommonWordPrefix(t *testing.T) {
+
+	cases := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"Trip 2024 - beach", "Trip 2024 - hotel"}, "Trip 2024 - "},
+		{[]string{"IMG_0001", "IMG_0002"}, "IMG_"},
+		{[]string{"report", "reports"}, ""},
+		{[]string{"alpha", "beta"}, ""},
+		{[]string{"only_one"}, ""},
+	}
+
+	for _, c := range cases {
+		if got := commonWordPrefix(c.names); got != c.want {
+			t.Errorf("commonWordPrefix(%q) = %q, want %q", c.names, got, c.want)
+		}
+	}
+}
+
+func TestStripCommonPrefix(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"Holiday 2023 - beach.jpg": "a",
+		"Holiday 2023 - hotel.jpg": "b",
+		"Holiday 2023 - .txt":      "nothing after the prefix",
+	})
+
+	mapping, err := stripCommonPrefix(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"Holiday 2023 - beach.jpg": "beach.jpg", "Holiday 2023 - hotel.jpg": "hotel.jpg"}
+	if !reflect.DeepEqual(mapping, want) {
+		t.Errorf("mapping = %v, want %v", mapping, want)
+	}
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"Holiday 2023 - .txt", "beach.jpg", "hotel.jpg"}) {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestStripCommonPrefixNoCommonPrefix(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"alpha.txt": "", "beta.txt": ""})
+
+	mapping, err := stripCommonPrefix(dir)
+	if err != nil || len(mapping) != 0 {
+		t.Errorf("mapping = %v, %v, want nothing renamed", mapping, err)
+	}
+}
+
+func TestStripCommonPrefixKeepsSkippedOwnersNames(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a_.txt":     "skipped, keeps its name",
+		"a_a_.txt":   "would land on a_.txt",
+		"a_a_a_.txt": "would land on a_a_.txt once that moved",
+		"a_b.txt":    "free to move",
+		"a_a_b.txt":  "lands on a_b.txt, which moves away",
+	})
+
+	mapping, err := stripCommonPrefix(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"a_b.txt": "b.txt", "a_a_b.txt": "a_b.txt"}
+	if !reflect.DeepEqual(mapping, want) {
+		t.Errorf("mapping = %v, want %v", mapping, want)
+	}
+
+	for name, content := range map[string]string{
+		"a_.txt":     "skipped, keeps its name",
+		"a_a_.txt":   "would land on a_.txt",
+		"a_a_a_.txt": "would land on a_a_.txt once that moved",
+		"b.txt":      "free to move",
+		"a_b.txt":    "lands on a_b.txt, which moves away",
+	} {
+		if got := readTestFile(t, dir, name); got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+}
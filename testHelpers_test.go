@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Function to create files (slash-separated paths) with the given contents under dir
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Function to list every regular file under dir as sorted slash-separated paths
+func listTestFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	var names []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			rel, _ := filepath.Rel(dir, path)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Function to read a file under dir, failing the test if it can't
+func readTestFile(t *testing.T, dir string, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+// Function to backdate a file under dir
+func backdate(t *testing.T, dir string, name string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.Chtimes(filepath.Join(dir, filepath.FromSlash(name)), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Function to compare two string slices in order
+func equalStrings(a []string, b []string) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Returned by moveToTrash on platforms without a supported trash
+var errTrashUnsupported = errors.New("no trash is available on this platform")
+
+// Function to delete a file, sending it to the OS trash when opts.UseTrash is
+// set. Where there is no trash the file is deleted permanently with a warning.
+func removeFile(path string, opts Options) error {
+
+	if !opts.UseTrash {
+		return os.Remove(path)
+	}
+
+	err := moveToTrash(path)
+	if errors.Is(err, errTrashUnsupported) {
+		fmt.Printf("Warning: %v; deleting %s permanently\n", err, path)
+		return os.Remove(path)
+	}
+
+	return err
+}
+
+// Function to clear a file that renaming src to dst would replace, through
+// removeFile, so with opts.UseTrash an overwrite can be undone like any other
+// deletion. Without UseTrash the rename replaces it as before.
+func removeOverwritten(src string, dst string, opts Options) error {
+
+	if !opts.UseTrash {
+		return nil
+	}
+
+	dstInfo, err := os.Lstat(dst)
+	if err != nil || dstInfo.IsDir() {
+		return nil
+	}
+	if srcInfo, err := os.Lstat(src); err == nil && os.SameFile(srcInfo, dstInfo) {
+		return nil
+	}
+
+	return removeFile(dst, opts)
+}
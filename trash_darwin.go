@@ -0,0 +1,24 @@
+//go:build darwin
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Function to move a file into the user's ~/.Trash
+func moveToTrash(path string) error {
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	trash := filepath.Join(home, ".Trash")
+	if err := os.MkdirAll(trash, 0700); err != nil {
+		return err
+	}
+
+	return renameOrCopy(path, uniquePath(filepath.Join(trash, filepath.Base(path))), Options{})
+}
@@ -0,0 +1,84 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Function to find the home trash from the freedesktop.org trash spec
+func trashDir() (string, error) {
+
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "Trash"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+// Function to move a file into the freedesktop.org home trash, writing the
+// .trashinfo record file managers use to restore it
+func moveToTrash(path string) error {
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	trash, err := trashDir()
+	if err != nil {
+		return err
+	}
+	filesDir := filepath.Join(trash, "files")
+	infoDir := filepath.Join(trash, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	// The spec reserves a name by creating its info file exclusively
+	claimed := make(map[string]bool)
+	for {
+		target := uniquePathAvoiding(filepath.Join(filesDir, filepath.Base(absPath)), claimed)
+		infoPath := filepath.Join(infoDir, filepath.Base(target)+".trashinfo")
+
+		info, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			claimed[target] = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		segments := strings.Split(absPath, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		_, err = fmt.Fprintf(info, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+			strings.Join(segments, "/"), time.Now().Format("2006-01-02T15:04:05"))
+		if closeErr := info.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = renameOrCopy(absPath, target, Options{})
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return err
+		}
+
+		return nil
+	}
+}
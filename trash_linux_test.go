@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Function to point the freedesktop.org trash at a temporary folder
+func tempTrash(t *testing.T) string {
+
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	return filepath.Join(dataHome, "Trash")
+}
+
+func TestRemoveFileUsesTrash(t *testing.T) {
+
+	trash := tempTrash(t)
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"my file.txt": "first", "sub/my file.txt": "second"})
+
+	for _, name := range []string{"my file.txt", "sub/my file.txt"} {
+		if err := removeFile(filepath.Join(dir, filepath.FromSlash(name)), Options{UseTrash: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := listTestFiles(t, dir); len(got) != 0 {
+		t.Errorf("files left behind: %v", got)
+	}
+
+	// Same-named files get their own slots, each with a restore record
+	want := []string{"files/my file.txt", "files/my file_1.txt", "info/my file.txt.trashinfo", "info/my file_1.txt.trashinfo"}
+	if got := listTestFiles(t, trash); !equalStrings(got, want) {
+		t.Fatalf("trash = %v, want %v", got, want)
+	}
+	if got := readTestFile(t, trash, "files/my file_1.txt"); got != "second" {
+		t.Errorf("second trashed file = %q", got)
+	}
+
+	info := readTestFile(t, trash, "info/my file.txt.trashinfo")
+	wantPath := "Path=" + strings.ReplaceAll(filepath.Join(dir, "my file.txt"), " ", "%20") + "\n"
+	if !strings.HasPrefix(info, "[Trash Info]\n") || !strings.Contains(info, wantPath) || !strings.Contains(info, "DeletionDate=") {
+		t.Errorf("trashinfo = %q, want %q", info, wantPath)
+	}
+}
+
+func TestOverwriteGoesToTrash(t *testing.T) {
+
+	trash := tempTrash(t)
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"new.txt": "new", "old.txt": "old"})
+
+	if err := renameOrCopy(filepath.Join(dir, "new.txt"), filepath.Join(dir, "old.txt"), Options{UseTrash: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := listTestFiles(t, dir); !equalStrings(got, []string{"old.txt"}) {
+		t.Errorf("files = %v", got)
+	}
+	if got := readTestFile(t, dir, "old.txt"); got != "new" {
+		t.Errorf("old.txt = %q", got)
+	}
+	if got := readTestFile(t, trash, "files/old.txt"); got != "old" {
+		t.Errorf("trashed copy = %q, want the overwritten content", got)
+	}
+}
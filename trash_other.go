@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !(windows && (amd64 || arm64))
+
+package main
+
+// Function to move a file to the trash; not available on this platform
+func moveToTrash(path string) error {
+	return errTrashUnsupported
+}
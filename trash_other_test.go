@@ -0,0 +1,21 @@
+//go:build !linux && !darwin && !(windows && (amd64 || arm64))
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveFileFallsBackWithoutTrash(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a"})
+
+	if err := removeFile(filepath.Join(dir, "a.txt"), Options{UseTrash: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := listTestFiles(t, dir); len(got) != 0 {
+		t.Errorf("files = %v, want the file deleted permanently", got)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveFileWithoutTrash(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a"})
+
+	if err := removeFile(filepath.Join(dir, "a.txt"), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := listTestFiles(t, dir); len(got) != 0 {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestRenameOrCopyOverwriteWithoutTrash(t *testing.T) {
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"new.txt": "new", "old.txt": "old"})
+
+	if err := renameOrCopy(filepath.Join(dir, "new.txt"), filepath.Join(dir, "old.txt"), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestFile(t, dir, "old.txt"); got != "new" {
+		t.Errorf("old.txt = %q", got)
+	}
+}